@@ -0,0 +1,198 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// addCmd groups the commands that grow an existing project.
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "add to an existing project.",
+	Long:  `add to an existing project.`,
+}
+
+var (
+	addDir      string
+	addType     string
+	addArch     string
+	addRouter   string
+	addPort     int
+	addDB       string
+	addEntities []string
+	addFeatures []string
+)
+
+// addServiceCmd appends a service to a monorepo.
+var addServiceCmd = &cobra.Command{
+	Use:   "service <name>",
+	Short: "add a service to a monorepo.",
+	Long: `add a service to a monorepo.
+
+The service is appended to the services of the root project.yaml, its
+module is generated under services/<name> and added to go.work, and the
+root Makefile and docker-compose.yml are rewritten to include it. Left out,
+the type, arch and router default to those of the root project and the
+port to the first free one from 8080, as for the services already there.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		features, err := resolveFeatures(nil, addFeatures)
+		if err != nil {
+			return err
+		}
+
+		service := parser.Service{
+			Name:     args[0],
+			Type:     addType,
+			Arch:     addArch,
+			Port:     addPort,
+			Database: addDB,
+			Router:   addRouter,
+			Features: specFeatures(features),
+		}
+		for _, entity := range addEntities {
+			service.Entities = append(service.Entities, parser.Entity{Name: entity})
+		}
+		return addService(addDir, service, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.AddCommand(addServiceCmd)
+
+	addServiceCmd.Flags().StringVar(&addDir, "dir", ".", "root directory of the monorepo")
+	addServiceCmd.Flags().StringVar(&addType, "type", "", "type of the service (default: that of the root project)")
+	addServiceCmd.Flags().StringVar(&addArch, "arch", "", "architecture of the service")
+	addServiceCmd.Flags().StringVar(&addRouter, "router", "", "router of the service (default: that of the root project)")
+	addServiceCmd.Flags().IntVar(&addPort, "port", 0, "port of the service (default: the first free one from 8080)")
+	addServiceCmd.Flags().StringVar(&addDB, "db", "", "database of the service")
+	addServiceCmd.Flags().StringSliceVar(&addEntities, "entity", nil, "entities of the service")
+	addServiceCmd.Flags().StringArrayVar(&addFeatures, "feature", nil, "enable a feature as kind[=provider], e.g. cache=redis")
+}
+
+// addService appends service to the monorepo in dir and generates it. The
+// spec is only rewritten once the service has been generated.
+func addService(dir string, service parser.Service, out io.Writer) error {
+	specPath := filepath.Join(dir, "project.yaml")
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	config, err := parser.DecodeYAML(data, specPath)
+	if err != nil {
+		return err
+	}
+	if len(config.Services) == 0 {
+		return fmt.Errorf("%s declares no services; services can only be added to a monorepo", specPath)
+	}
+	if _, ok := config.Service(service.Name); ok {
+		return fmt.Errorf("service %q already exists", service.Name)
+	}
+
+	data, err = appendService(data, service)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specPath, err)
+	}
+	// The new spec is checked as a whole, for ports and names it shares
+	// with the other services.
+	config, err = parser.DecodeYAML(data, specPath)
+	if err != nil {
+		return err
+	}
+	services, err := resolveServices(config)
+	if err != nil {
+		return err
+	}
+
+	added := services[len(services)-1]
+	moduleDir := filepath.Join(dir, filepath.FromSlash(added.Dir))
+	if _, err := os.Stat(moduleDir); err == nil {
+		return fmt.Errorf("%s already exists", moduleDir)
+	}
+	if !generateService(dir, specPath, added, out) {
+		return fmt.Errorf("could not generate service %q", service.Name)
+	}
+
+	if err := os.WriteFile(specPath, data, 0644); err != nil {
+		return err
+	}
+	workPath := filepath.Join(dir, "go.work")
+	work, err := os.ReadFile(workPath)
+	switch {
+	case err == nil:
+		work = []byte(addWorkUse(string(work), added.Dir))
+	case os.IsNotExist(err):
+		work = []byte(goWork(services))
+	default:
+		return err
+	}
+	if err := os.WriteFile(workPath, work, 0644); err != nil {
+		return err
+	}
+	if err := updateMonorepo(dir, config.Project.Name, services); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✓ Added service '%s' in %s\n", service.Name, added.Dir)
+	return nil
+}
+
+// appendService adds service to the end of the services list of the spec
+// in data, keeping its comments.
+func appendService(data []byte, service parser.Service) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping")
+	}
+	list := mappingValue(doc.Content[0], "services")
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("services is not a list")
+	}
+
+	var item yaml.Node
+	if err := item.Encode(service); err != nil {
+		return nil, err
+	}
+	list.Content = append(list.Content, &item)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// specFeatures writes resolved features back as the features of a spec.
+func specFeatures(features FeaturesData) parser.Features {
+	var spec parser.Features
+	if features.Cache != nil {
+		spec.Cache.Name = features.Cache.Provider
+	}
+	if features.Queue != nil {
+		spec.Queue.Name = features.Queue.Provider
+	}
+	if features.Auth != nil {
+		spec.Auth.Name = features.Auth.Provider
+	}
+	return spec
+}
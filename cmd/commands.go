@@ -0,0 +1,171 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// commandPlaceholder is replaced by the file name of each command in the
+// paths of CLI templates, e.g. cmd/commandname.go.
+const commandPlaceholder = "commandname"
+
+// defaultCommand is generated for CLI projects that declare no commands,
+// so that they have one command to wire end to end.
+var defaultCommand = parser.Command{
+	Name:        "hello",
+	Description: "Greet someone",
+	Flags:       []parser.Flag{{Name: "name", Short: "n", Default: "world", Description: "who to greet"}},
+}
+
+// CommandData is a subcommand of a CLI project as the templates need it.
+type CommandData struct {
+	Name        string // e.g. list-users
+	Description string
+	Use         string // cobra usage line, e.g. "list-users <org>"
+	File        string // e.g. list_users
+	Func        string // Go name, e.g. ListUsers
+	Var         string // unexported Go name, e.g. listUsers
+	Args        []ArgData
+	Flags       []FlagData
+}
+
+// ArgData is a positional argument of a command.
+type ArgData struct {
+	Name  string
+	Field string // field of the command's options
+}
+
+// FlagData is a flag of a command.
+type FlagData struct {
+	Spec        parser.Flag
+	Name        string
+	Field       string // field of the command's options
+	Short       string
+	Description string
+	GoType      string
+	Method      string // pflag method defining it, e.g. StringVarP
+	// Default is the Go expression of the default value, and Printed the
+	// default as fmt's %v prints it, which golden files record.
+	Default string
+	Printed string
+}
+
+// flagKinds are the Go types and pflag methods of each flag type.
+var flagKinds = map[string]struct{ GoType, Method string }{
+	"string":   {"string", "StringVarP"},
+	"int":      {"int", "IntVarP"},
+	"bool":     {"bool", "BoolVarP"},
+	"duration": {"time.Duration", "DurationVarP"},
+	"strings":  {"[]string", "StringSliceVarP"},
+}
+
+// resolveCommands prepares the commands declared in project.yaml, or the
+// default one. The config must have been validated.
+func resolveCommands(yamlConfig *parser.Config) []CommandData {
+	specs := []parser.Command{defaultCommand}
+	if yamlConfig != nil && len(yamlConfig.Commands) > 0 {
+		specs = yamlConfig.Commands
+	}
+
+	commands := make([]CommandData, 0, len(specs))
+	for _, spec := range specs {
+		cd := CommandData{
+			Name:        spec.Name,
+			Description: first(spec.Description, "Run "+spec.Name),
+			Use:         spec.Name,
+			File:        naming.Snake(spec.Name),
+			Func:        naming.Pascal(spec.Name),
+			Var:         naming.Camel(spec.Name),
+		}
+		for _, arg := range spec.Args {
+			cd.Use += " <" + arg + ">"
+			cd.Args = append(cd.Args, ArgData{Name: arg, Field: naming.Pascal(arg)})
+		}
+		for _, flag := range spec.Flags {
+			kind := flagKinds[flag.FlagType()]
+			fd := FlagData{
+				Spec:        flag,
+				Name:        flag.Name,
+				Field:       naming.Pascal(flag.Name),
+				Short:       flag.Short,
+				Description: flag.Description,
+				GoType:      kind.GoType,
+				Method:      kind.Method,
+			}
+			fd.Default, fd.Printed = flagDefault(flag.FlagType(), flag.Default)
+			cd.Flags = append(cd.Flags, fd)
+		}
+		commands = append(commands, cd)
+	}
+	return commands
+}
+
+// UsesTime reports whether a flag of the command is a duration, so that
+// its file imports time.
+func (c CommandData) UsesTime() bool {
+	for _, flag := range c.Flags {
+		if flag.GoType == "time.Duration" {
+			return true
+		}
+	}
+	return false
+}
+
+// flagDefault returns the Go expression of a flag's default value, which
+// must parse as flagType, and the default as fmt's %v prints it.
+func flagDefault(flagType, value string) (expr, printed string) {
+	switch flagType {
+	case "int":
+		n, _ := strconv.Atoi(value)
+		return strconv.Itoa(n), strconv.Itoa(n)
+	case "bool":
+		b, _ := strconv.ParseBool(value)
+		return strconv.FormatBool(b), strconv.FormatBool(b)
+	case "duration":
+		d, _ := time.ParseDuration(value)
+		return durationExpr(d), d.String()
+	case "strings":
+		if value == "" {
+			return "nil", "[]"
+		}
+		items := strings.Split(value, ",")
+		quoted := make([]string, len(items))
+		for i, item := range items {
+			quoted[i] = strconv.Quote(item)
+		}
+		return "[]string{" + strings.Join(quoted, ", ") + "}", fmt.Sprint(items)
+	default:
+		return strconv.Quote(value), value
+	}
+}
+
+// durationExpr writes d in the largest unit that divides it, e.g.
+// 90 * time.Second.
+func durationExpr(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.d, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d", int64(d))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/addons"
+	"github.com/upsaurav12/bootstrap/pkg/compose"
+)
+
+// appServiceName is the compose service of the generated application.
+const appServiceName = "app"
+
+// appFragment describes the generated application itself. Inside the compose
+// network it reaches its dependencies by service name and container port,
+// which override the host-oriented values in .env.
+func appFragment(healthcheck []string, stores []StoreData, extras []addons.ServiceAddOnConfig) compose.Fragment {
+	svc := compose.Service{
+		Build:       compose.BuildSpec{Context: "."},
+		Restart:     "unless-stopped",
+		EnvFile:     []string{".env"},
+		Environment: map[string]string{},
+		Ports:       []string{"${PORT}:${PORT}"},
+		Healthcheck: &compose.Healthcheck{
+			Test:     healthcheck,
+			Interval: "10s",
+			Timeout:  "3s",
+			Retries:  5,
+		},
+	}
+
+	var volumes []string
+	for _, store := range stores {
+		if _, ok := store.Config.Fragment(); ok {
+			svc.Environment[store.Prefix+"_HOST"] = store.Config.ServiceName
+			svc.Environment[store.Prefix+"_PORT"] = store.Config.Port
+			continue
+		}
+		if store.Config.Volume == "" {
+			continue
+		}
+
+		// Embedded databases keep their file in a volume on the app.
+		_, mount, _ := strings.Cut(store.Config.Volume, ":")
+		svc.Environment[store.Prefix+"_DATABASE"] = mount + "/" + store.Database
+		if !slices.Contains(volumes, store.Config.VolumeName) {
+			svc.Volumes = append(svc.Volumes, store.Config.Volume)
+			volumes = append(volumes, store.Config.VolumeName)
+		}
+	}
+
+	for _, extra := range extras {
+		svc.Environment[extra.EnvKey] = extra.Address
+	}
+
+	return compose.Fragment{Name: appServiceName, Service: svc, Volumes: volumes}
+}
+
+// writeCompose assembles docker-compose.yml from the app, checked with the
+// healthcheck test, its datastores and any extra backing services, and
+// writes it once it has been validated.
+func writeCompose(projectDir string, healthcheck []string, stores []StoreData, extras ...addons.ServiceAddOnConfig) error {
+	file, err := compose.Build(appFragment(healthcheck, stores, extras), composeDeps(stores, extras)...)
+	if err != nil {
+		return err
+	}
+
+	content, err := file.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), content, 0644)
+}
+
+// composeDeps returns the compose services of the datastores and the extra
+// backing services of an app.
+func composeDeps(stores []StoreData, extras []addons.ServiceAddOnConfig) []compose.Fragment {
+	var deps []compose.Fragment
+	for _, store := range stores {
+		if fragment, ok := store.Config.Fragment(); ok {
+			deps = append(deps, fragment)
+		}
+	}
+	for _, extra := range extras {
+		deps = append(deps, extra.Fragment())
+	}
+	return deps
+}
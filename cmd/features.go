@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/addons"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// defaultProviders are used by --feature flags that name only the kind,
+// e.g. --feature cache.
+var defaultProviders = map[string]string{"cache": "redis", "queue": "nats", "auth": "jwt"}
+
+// FeatureData is an enabled feature as the templates need it.
+type FeatureData struct {
+	Kind     string // cache, queue or auth
+	Provider string // e.g. redis
+	EnvKey   string
+	// EnvValue is the value of EnvKey in .env, for running on the host.
+	EnvValue string
+	// Service is the backing service added to docker-compose.yml, if any.
+	Service *addons.ServiceAddOnConfig
+}
+
+// FeaturesData holds the enabled features; disabled ones are nil.
+type FeaturesData struct {
+	Cache *FeatureData
+	Queue *FeatureData
+	Auth  *FeatureData
+}
+
+// All returns the enabled features, in a fixed order.
+func (f FeaturesData) All() []FeatureData {
+	var all []FeatureData
+	for _, feature := range []*FeatureData{f.Cache, f.Queue, f.Auth} {
+		if feature != nil {
+			all = append(all, *feature)
+		}
+	}
+	return all
+}
+
+// Any reports whether any feature is enabled.
+func (f FeaturesData) Any() bool {
+	return len(f.All()) > 0
+}
+
+// Services returns the backing services of the enabled features.
+func (f FeaturesData) Services() []addons.ServiceAddOnConfig {
+	var services []addons.ServiceAddOnConfig
+	for _, feature := range f.All() {
+		if feature.Service != nil {
+			services = append(services, *feature.Service)
+		}
+	}
+	return services
+}
+
+// templateJobs renders the files of every enabled feature into
+// internal/<kind> of the project.
+func (f FeaturesData) templateJobs(projectDir string) []TemplateJob {
+	var jobs []TemplateJob
+	for _, feature := range f.All() {
+		jobs = append(jobs, TemplateJob{
+			TemplateDir: "features/" + feature.Kind + "/" + feature.Provider,
+			DestDir:     filepath.Join(projectDir, "internal", feature.Kind),
+		})
+	}
+	return jobs
+}
+
+// resolveFeatures merges the features: section of project.yaml with the
+// --feature flags, which win, and looks every provider up in the addon
+// registries. Flags are written kind=provider, or just kind for the
+// default provider.
+func resolveFeatures(yamlConfig *parser.Config, flags []string) (FeaturesData, error) {
+	enabled := map[string]string{}
+	if yamlConfig != nil {
+		enabled = yamlConfig.Features.Enabled()
+	}
+
+	providers := addons.FeatureProviders()
+	for _, flag := range flags {
+		kind, provider, _ := strings.Cut(flag, "=")
+		if _, ok := providers[kind]; !ok {
+			return FeaturesData{}, fmt.Errorf("unknown feature %q (expected one of %s)", kind, strings.Join(featureKinds(), ", "))
+		}
+		if provider == "" {
+			provider = defaultProviders[kind]
+		}
+		enabled[kind] = provider
+	}
+
+	var features FeaturesData
+	for kind, provider := range enabled {
+		if !slices.Contains(providers[kind], provider) {
+			return FeaturesData{}, fmt.Errorf("unknown %s provider %q (expected one of %s)",
+				kind, provider, strings.Join(providers[kind], ", "))
+		}
+
+		feature := &FeatureData{Kind: kind, Provider: provider}
+		switch kind {
+		case "cache":
+			svc := addons.CacheRegistory[provider]
+			feature.Service, feature.EnvKey, feature.EnvValue = &svc, svc.EnvKey, svc.LocalAddress
+			features.Cache = feature
+		case "queue":
+			svc := addons.BrokerRegistory[provider]
+			feature.Service, feature.EnvKey, feature.EnvValue = &svc, svc.EnvKey, svc.LocalAddress
+			features.Queue = feature
+		case "auth":
+			auth := addons.AuthRegistory[provider]
+			feature.EnvKey, feature.EnvValue = auth.EnvKey, auth.LocalSecret
+			features.Auth = feature
+		}
+	}
+	return features, nil
+}
+
+func featureKinds() []string {
+	kinds := make([]string, 0, len(defaultProviders))
+	for kind := range addons.FeatureProviders() {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,257 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// defaultFields are given to entities declared without fields, such as the
+// ones passed with --entities.
+var defaultFields = []parser.Field{{Name: "name", Type: "string"}}
+
+// FieldData is an entity field as the model and DTO templates need it.
+type FieldData struct {
+	Name   string // Go field name
+	JSON   string
+	Column string
+	Type   string // Go type on the model
+	Tag    string // model struct tag
+	// UpdateType is the type of the field in update requests, where nil
+	// means "leave unchanged"; Deref reports whether it points to Type.
+	UpdateType string
+	Deref      bool
+	// CreateRules and UpdateRules are the validate tags of the field in
+	// create and update requests; Pattern names its regex rule, if any.
+	CreateRules string
+	UpdateRules string
+	Pattern     *PatternData
+	Spec        parser.Field
+}
+
+// PatternData is a regex rule, registered with the validator under Key.
+type PatternData struct {
+	Key   string
+	Regex string // quoted Go string literal
+}
+
+// declaredFields returns the fields of entity as declared in project.yaml,
+// or the default fields when it has none.
+func declaredFields(yamlConfig *parser.Config, entity string) []parser.Field {
+	if yamlConfig != nil {
+		if spec, ok := yamlConfig.Entity(entity); ok && len(spec.Fields) > 0 {
+			return spec.Fields
+		}
+	}
+	return defaultFields
+}
+
+// fieldData prepares fields for the templates. The fields must have been
+// checked with parser.Entity.CheckFields.
+func fieldData(fields []parser.Field) []FieldData {
+	out := make([]FieldData, 0, len(fields))
+	for _, field := range fields {
+		ft := parser.FieldTypes[field.Type]
+
+		fd := FieldData{
+			Name:   naming.Pascal(field.Name),
+			JSON:   field.JSONName(),
+			Column: naming.Snake(field.Name),
+			Type:   ft.Go,
+			Spec:   field,
+		}
+		if field.Nullable && !ft.Nilable {
+			fd.Type = "*" + ft.Go
+		}
+
+		fd.UpdateType = fd.Type
+		if !field.Nullable && !ft.Nilable {
+			fd.UpdateType = "*" + ft.Go
+			fd.Deref = true
+		}
+
+		settings := []string{"column:" + fd.Column}
+		if ft.Tag != "" {
+			settings = append(settings, ft.Tag)
+		}
+		if !field.Nullable {
+			settings = append(settings, "not null")
+		}
+		if field.Default != "" {
+			settings = append(settings, "default:"+field.Default)
+		}
+		switch {
+		case field.Unique:
+			settings = append(settings, "uniqueIndex")
+		case field.Index:
+			settings = append(settings, "index")
+		}
+		fd.Tag = `gorm:"` + strings.Join(settings, ";") + `" json:"` + fd.JSON + `"`
+
+		out = append(out, fd)
+	}
+	return out
+}
+
+// Conflicts returns the Go condition under which the records a and b hold
+// the same value of the field, as a unique index sees it: NULLs, and so nil
+// values, never collide.
+func (fd FieldData) Conflicts(a, b string) string {
+	x, y := a+"."+fd.Name, b+"."+fd.Name
+	nilable := parser.FieldTypes[fd.Spec.Type].Nilable
+	deref := strings.HasPrefix(fd.Type, "*")
+
+	var same string
+	switch {
+	case nilable:
+		same = "string(" + x + ") == string(" + y + ")"
+	case fd.Spec.Type == "time" && deref:
+		same = x + ".Equal(*" + y + ")"
+	case fd.Spec.Type == "time":
+		same = x + ".Equal(" + y + ")"
+	case deref:
+		same = "*" + x + " == *" + y
+	default:
+		same = x + " == " + y
+	}
+	if nilable || deref {
+		return x + " != nil && " + y + " != nil && " + same
+	}
+	return same
+}
+
+// Distinct returns a Go expression for a value of the field that differs
+// for every id, a uint expression, so that the records of generated tests
+// keep clear of each other's unique values. It returns "" for fields whose
+// zero value is nil, which never clashes.
+func (fd FieldData) Distinct(id string) string {
+	if parser.FieldTypes[fd.Spec.Type].Nilable || strings.HasPrefix(fd.Type, "*") {
+		return ""
+	}
+	switch fd.Spec.Type {
+	case "int", "int64", "float":
+		return fd.Type + "(" + id + ")"
+	case "decimal":
+		return "float64(" + id + ")"
+	case "bool":
+		return id + "%2 == 1"
+	case "time":
+		return "time.Unix(int64(" + id + "), 0)"
+	default:
+		return "fmt.Sprint(" + id + ")"
+	}
+}
+
+// distinctImports lists the packages the Distinct values of the unique
+// fields need, sorted.
+func distinctImports(unique []FieldData) []string {
+	seen := map[string]bool{}
+	for _, fd := range unique {
+		switch value := fd.Distinct("id"); {
+		case strings.HasPrefix(value, "fmt."):
+			seen["fmt"] = true
+		case strings.HasPrefix(value, "time."):
+			seen["time"] = true
+		}
+	}
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// addRules fills in the validate tags of the fields of entity from the
+// rules declared in project.yaml. Fields without rules are left untouched.
+func addRules(entity string, fields []FieldData) {
+	for i := range fields {
+		fd := &fields[i]
+		r := fd.Spec.Validate
+		if r == nil {
+			continue
+		}
+
+		var rules []string
+		if r.Min != nil {
+			rules = append(rules, "min="+strconv.FormatFloat(*r.Min, 'f', -1, 64))
+		}
+		if r.Max != nil {
+			rules = append(rules, "max="+strconv.FormatFloat(*r.Max, 'f', -1, 64))
+		}
+		if r.Email {
+			rules = append(rules, "email")
+		}
+		if len(r.OneOf) > 0 {
+			rules = append(rules, "oneof="+strings.Join(r.OneOf, " "))
+		}
+		if r.Regex != "" {
+			fd.Pattern = &PatternData{Key: naming.Snake(entity) + "_" + fd.Column, Regex: strconv.Quote(r.Regex)}
+			rules = append(rules, "pattern="+fd.Pattern.Key)
+		}
+
+		// Create requests must carry required fields; rules on the others
+		// only apply when a value is given. Update requests may leave any
+		// field out, but a given value still has to pass every rule and,
+		// for required fields, must not be empty.
+		create := append([]string{"omitempty"}, rules...)
+		if r.Required {
+			create = append([]string{"required"}, rules...)
+		}
+		update := append([]string{"omitempty"}, rules...)
+		if r.Required {
+			update = append([]string{"omitempty", "nonzero"}, rules...)
+		}
+		fd.CreateRules = strings.Join(create, ",")
+		fd.UpdateRules = strings.Join(update, ",")
+	}
+}
+
+// entityFieldData returns the fields of entity as the templates need them,
+// followed by the foreign keys of its relations.
+func entityFieldData(entity string, fields []parser.Field, rd *RelationData) []FieldData {
+	out := fieldData(fields)
+	addRules(entity, out)
+	if rd != nil {
+		for _, fk := range rd.ForeignKeys {
+			out = append(out, fk.Field)
+		}
+	}
+	return out
+}
+
+// fieldImports lists the packages the Go types of fields need, sorted.
+func fieldImports(fields []parser.Field, always ...string) []string {
+	seen := map[string]bool{}
+	for _, imp := range always {
+		seen[imp] = true
+	}
+	for _, field := range fields {
+		if imp := parser.FieldTypes[field.Type].Import; imp != "" {
+			seen[imp] = true
+		}
+	}
+
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// tableName is the table of an entity: the snake-cased plural of its name.
+func tableName(entity string) string {
+	return naming.Plural(naming.Snake(entity))
+}
+
+// resourcePath is the URL segment of an entity, e.g. order-items.
+func resourcePath(entity string) string {
+	return strings.ReplaceAll(tableName(entity), "_", "-")
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+)
+
+// graphqlScalar is how values of a field type are carried in the schema and
+// by the resolvers.
+type graphqlScalar struct {
+	Name string // GraphQL type
+	Go   string // Go type the resolvers use for it
+	// Conv is how values are converted to and from the model's Go type:
+	// "" when they are the same type, "number" for conversions between
+	// number types, or the type whose helpers convert them.
+	Conv string
+}
+
+// graphqlScalars maps field types to their GraphQL scalars. Foreign keys are
+// IDs.
+var graphqlScalars = map[string]graphqlScalar{
+	"string":  {"String", "string", ""},
+	"text":    {"String", "string", ""},
+	"uuid":    {"String", "string", ""},
+	"float":   {"Float", "float64", ""},
+	"decimal": {"Float", "float64", ""},
+	"bool":    {"Boolean", "bool", ""},
+	"int":     {"Int", "int32", "number"},
+	"int64":   {"Int64", "Int64", "number"},
+	"time":    {"Time", "graphql.Time", "time"},
+	"json":    {"JSON", "JSON", "json"},
+	"bytes":   {"Bytes", "Bytes", "bytes"},
+	"":        {"ID", "ID", "number"},
+}
+
+// GraphQLFieldData is an entity field as the schema and resolver templates
+// need it.
+type GraphQLFieldData struct {
+	FieldData
+	GQL    string // name in the schema, e.g. releasedAt
+	GoName string // resolver method and input field, e.g. ReleasedAt
+	// Type, CreateType and UpdateType are the GraphQL types of the field on
+	// the object and in the create and update inputs, and GoType, CreateGo
+	// and UpdateGo their Go types.
+	Type, CreateType, UpdateType string
+	GoType, CreateGo, UpdateGo   string
+	// Resolve converts the model field to GoType, and ToCreate and ToUpdate
+	// the input fields to the DTOs'; %s is the value.
+	Resolve, ToCreate, ToUpdate string
+}
+
+// graphqlName is the name of a field in the schema: "user_id" -> "userId".
+func graphqlName(s string) string {
+	var b strings.Builder
+	for i, w := range naming.Words(s) {
+		r := []rune(w)
+		if i > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// graphqlFields prepares fields for the GraphQL templates. Create inputs
+// may leave out the fields that are nullable or have a default.
+func graphqlFields(fields []FieldData) []GraphQLFieldData {
+	out := make([]GraphQLFieldData, 0, len(fields))
+	for _, fd := range fields {
+		scalar := graphqlScalars[fd.Spec.Type]
+		model := strings.TrimPrefix(fd.Type, "*")
+		nullable := strings.HasPrefix(fd.Type, "*") || fd.Spec.Nullable
+		optional := nullable || fd.Spec.Default != ""
+
+		gf := GraphQLFieldData{
+			FieldData:  fd,
+			GQL:        graphqlName(fd.JSON),
+			GoName:     naming.Pascal(fd.JSON),
+			Type:       nonNull(scalar.Name, !nullable),
+			CreateType: nonNull(scalar.Name, !optional),
+			UpdateType: scalar.Name,
+			GoType:     pointer(scalar.Go, nullable),
+			CreateGo:   pointer(scalar.Go, optional),
+			UpdateGo:   "*" + scalar.Go,
+		}
+
+		switch scalar.Conv {
+		case "":
+			gf.Resolve, gf.ToCreate, gf.ToUpdate = "%s", "%s", "%s"
+			if optional && !nullable {
+				gf.ToCreate = "valueOf(%s)"
+			}
+		case "number":
+			gf.Resolve, gf.ToUpdate = scalar.Go+"(%s)", "convertPtr["+model+"](%s)"
+			if nullable {
+				gf.Resolve = "convertPtr[" + scalar.Go + "](%s)"
+			}
+			switch {
+			case !optional:
+				gf.ToCreate = model + "(%s)"
+			case nullable:
+				gf.ToCreate = "convertPtr[" + model + "](%s)"
+			default:
+				gf.ToCreate = model + "(valueOf(%s))"
+			}
+		case "time":
+			gf.Resolve, gf.ToCreate, gf.ToUpdate = "graphql.Time{Time: %s}", "%s.Time", "fromTimePtr(%s)"
+			switch {
+			case nullable:
+				gf.Resolve, gf.ToCreate = "toTimePtr(%s)", "fromTimePtr(%s)"
+			case optional:
+				gf.ToCreate = "valueOf(%s).Time"
+			}
+		case "json", "bytes":
+			// JSON and bytes are nil when unset, whether or not they are
+			// nullable.
+			gf.Resolve, gf.ToCreate, gf.ToUpdate = scalar.Go+"(%s)", "from"+scalar.Go+"(%s)", "from"+scalar.Go+"Ptr(%s)"
+			if nullable {
+				gf.Resolve = scalar.Conv + "Ptr(%s)"
+			}
+			if optional {
+				gf.ToCreate = gf.ToUpdate
+			}
+		}
+		out = append(out, gf)
+	}
+	return out
+}
+
+func nonNull(t string, required bool) string {
+	if required {
+		return t + "!"
+	}
+	return t
+}
+
+func pointer(t string, nullable bool) string {
+	if nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// graphqlHelpers lists the scalars the fields of every entity use and the
+// helpers their resolvers call, so that the GraphQL templates only declare
+// those.
+func graphqlHelpers(entities []string, entityFields map[string][]FieldData, relations map[string]*RelationData) map[string]bool {
+	used := map[string]bool{}
+	for _, entity := range entities {
+		for _, gf := range graphqlFields(entityFields[entity]) {
+			used[gf.UpdateType] = true
+			addHelpers(used, gf.Resolve, gf.ToCreate, gf.ToUpdate)
+		}
+		if rd := relations[entity]; rd != nil {
+			// Relations are loaded by the IDs of a batch, and
+			// many-to-many links are set by the IDs in inputs.
+			used["unique"] = used["unique"] || len(rd.Associations) > 0
+			used["toIDs"] = used["toIDs"] || len(rd.ManyToMany()) > 0
+		}
+	}
+	return used
+}
+
+// addHelpers records the functions that the conversions call.
+func addHelpers(used map[string]bool, convs ...string) {
+	for _, conv := range convs {
+		if name, _, ok := strings.Cut(conv, "("); ok && name != "" {
+			name, _, _ = strings.Cut(name, "[")
+			used[name] = true
+		}
+		// Conversions may wrap a helper, as in int(valueOf(%s)).
+		if strings.Contains(conv, "valueOf(") {
+			used["valueOf"] = true
+		}
+	}
+}
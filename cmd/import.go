@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/upsaurav12/bootstrap/pkg/ddl"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// importCmd groups the commands that turn existing artefacts into a project.yaml.
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "create a project.yaml from existing artefacts.",
+	Long:  `create a project.yaml from existing artefacts.`,
+}
+
+var importDialect string
+var importOut string
+var importName string
+var importRouter string
+var importPort int
+var importForce bool
+
+// importDDLCmd reverse-engineers entities from CREATE TABLE statements.
+var importDDLCmd = &cobra.Command{
+	Use:   "ddl <schema.sql>",
+	Short: "create a project.yaml from a SQL schema.",
+	Long: `create a project.yaml from a SQL schema.
+
+Every CREATE TABLE statement becomes an entity with typed fields, and
+single-column foreign keys become belongs_to relations. Statements and
+clauses that cannot be represented are reported with their line number.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dialect, err := ddl.ParseDialect(importDialect)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		tables, issues, err := ddl.Parse(string(src), dialect)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if len(tables) == 0 {
+			return fmt.Errorf("%s: no CREATE TABLE statements found", args[0])
+		}
+
+		entities, mapIssues := ddl.Entities(tables, dialect)
+		issues = append(issues, mapIssues...)
+		for _, issue := range issues {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s:%d: %s\n", args[0], issue.Line, issue.Message)
+		}
+
+		name := importName
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		}
+
+		config := parser.Config{
+			Project: parser.Project{
+				Name:     name,
+				Type:     "rest",
+				Port:     importPort,
+				Database: string(dialect),
+				Router:   importRouter,
+			},
+			Entities: entities,
+		}
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(config); err != nil {
+			return err
+		}
+
+		if !importForce {
+			if _, err := os.Stat(importOut); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", importOut)
+			}
+		}
+		if err := os.WriteFile(importOut, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Wrote %s with %d entities (%d issues)\n", importOut, len(entities), len(issues))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importDDLCmd)
+
+	importDDLCmd.Flags().StringVar(&importDialect, "dialect", "postgres", "SQL dialect of the schema (postgres, mysql, sqlite)")
+	importDDLCmd.Flags().StringVar(&importOut, "out", "project.yaml", "path of the project.yaml to write")
+	importDDLCmd.Flags().StringVar(&importName, "name", "", "project name (defaults to the schema file name)")
+	importDDLCmd.Flags().StringVar(&importRouter, "router", "gin", "router of the generated project")
+	importDDLCmd.Flags().IntVar(&importPort, "port", 8080, "port of the generated project")
+	importDDLCmd.Flags().BoolVar(&importForce, "force", false, "overwrite an existing output file")
+}
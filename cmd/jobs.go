@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// jobPlaceholder is replaced by the name of each job in the paths of
+// worker templates, e.g. internal/jobs/jobname.go.
+const jobPlaceholder = "jobname"
+
+// defaultJob is generated for worker projects that declare no jobs, so
+// that they have one job to wire end to end.
+var defaultJob = parser.Job{
+	Name: "send_email",
+	Payload: []parser.Field{
+		{Name: "to", Type: "string", Validate: &parser.Rules{Required: true, Email: true}},
+		{Name: "subject", Type: "string", Validate: &parser.Rules{Required: true}},
+		{Name: "body", Type: "text"},
+	},
+}
+
+// Defaults of the jobs that do not set their own limits.
+const (
+	defaultMaxAttempts = 5
+	defaultJobTimeout  = time.Minute
+)
+
+// JobData is a job of a worker project as the templates need it.
+type JobData struct {
+	Spec   parser.Job
+	Kind   string // name in the queue, e.g. send_email
+	Type   string // payload type, e.g. SendEmail
+	Var    string // unexported Go name, e.g. sendEmail
+	Fields []FieldData
+	// Patterns are the regex rules of the payload's fields.
+	Patterns []PatternData
+	// Imports are the standard packages the job's file needs.
+	Imports     []string
+	MaxAttempts int
+	Timeout     time.Duration
+	TimeoutExpr string // Go expression of Timeout, e.g. 30 * time.Second
+	Concurrency int
+}
+
+// resolveJobs prepares the jobs declared in project.yaml, or the default
+// one. The config must have been validated.
+func resolveJobs(yamlConfig *parser.Config) []JobData {
+	specs := []parser.Job{defaultJob}
+	if yamlConfig != nil && len(yamlConfig.Jobs) > 0 {
+		specs = yamlConfig.Jobs
+	}
+
+	jobs := make([]JobData, 0, len(specs))
+	for _, spec := range specs {
+		jd := JobData{
+			Spec:        spec,
+			Kind:        spec.Name,
+			Type:        naming.Pascal(spec.Name),
+			Var:         naming.Camel(spec.Name),
+			Fields:      fieldData(spec.Payload),
+			Imports:     fieldImports(spec.Payload, "context", "time"),
+			MaxAttempts: spec.MaxAttempts,
+			Timeout:     defaultJobTimeout,
+			Concurrency: spec.Concurrency,
+		}
+		addRules(spec.Name, jd.Fields)
+		for _, fd := range jd.Fields {
+			if fd.Pattern != nil {
+				jd.Patterns = append(jd.Patterns, *fd.Pattern)
+			}
+		}
+		if jd.MaxAttempts == 0 {
+			jd.MaxAttempts = defaultMaxAttempts
+		}
+		if spec.Timeout != "" {
+			jd.Timeout, _ = time.ParseDuration(spec.Timeout)
+		}
+		jd.TimeoutExpr = durationExpr(jd.Timeout)
+		jobs = append(jobs, jd)
+	}
+	return jobs
+}
+
+// workerSettings are the environment variables tuning the worker pool of
+// worker projects.
+func workerSettings() []SettingData {
+	return []SettingData{
+		{Key: "WORKER_CONCURRENCY", Local: "10", Default: "10", Shared: true},
+		{Key: "WORKER_POLL_INTERVAL", Local: "1s", Default: "1s", Shared: true},
+		{Key: "WORKER_DRAIN_TIMEOUT", Local: "30s", Default: "30s", Shared: true},
+	}
+}
+
+// jobsYAML writes the jobs: section of the project.yaml of worker projects.
+func jobsYAML(jobs []JobData) (string, error) {
+	specs := make([]parser.Job, len(jobs))
+	for i, job := range jobs {
+		specs[i] = job.Spec
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string][]parser.Job{"jobs": specs}); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// HasRequired reports whether the payload has required fields, so that an
+// empty payload does not validate.
+func (j JobData) HasRequired() bool {
+	for _, fd := range j.Fields {
+		if fd.Spec.Validate != nil && fd.Spec.Validate.Required {
+			return true
+		}
+	}
+	return false
+}
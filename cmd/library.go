@@ -0,0 +1,29 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+)
+
+// packageName is the Go package name of a library module: the words of its
+// name run together, "go-money" -> "gomoney", as package names take no
+// dashes or underscores. Names that do not start with a letter get a "lib"
+// prefix.
+func packageName(module string) string {
+	var b strings.Builder
+	for _, r := range strings.Join(naming.Words(module), "") {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "lib" + name
+	}
+	return name
+}
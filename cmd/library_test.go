@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		module string
+		want   string
+	}{
+		{"money", "money"},
+		{"go-money", "gomoney"},
+		{"http_client", "httpclient"},
+		{"OAuth2", "oauth2"},
+		{"3d-tools", "lib3dtools"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.module, func(t *testing.T) {
+			assert.Equal(t, tt.want, packageName(tt.module))
+		})
+	}
+}
+
+func TestCreateNewProject_Library(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir), "Failed to change to temp directory")
+
+	var out bytes.Buffer
+	require.True(t, createNewProject("go-money", "", "library", &out), out.String())
+	assert.Contains(t, out.String(), "Type:      library\n")
+	assert.NotContains(t, out.String(), "Port:")
+
+	for _, file := range []string{
+		"go.mod",
+		"doc.go",
+		"client.go",
+		"client_test.go",
+		"example_test.go",
+		"internal/normalize/normalize.go",
+		"internal/normalize/normalize_test.go",
+		"CHANGELOG.md",
+		"Makefile",
+		"README.md",
+	} {
+		_, err := os.Stat(filepath.Join("go-money", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	for _, file := range []string{"docker-compose.yml", "Dockerfile", "cmd", "internal/db", "internal/server"} {
+		_, err := os.Stat(filepath.Join("go-money", file))
+		assert.True(t, os.IsNotExist(err), "libraries have no %s", file)
+	}
+
+	doc, err := os.ReadFile(filepath.Join("go-money", "doc.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), "\npackage gomoney\n")
+
+	examples, err := os.ReadFile(filepath.Join("go-money", "example_test.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(examples), `gomoney "go-money"`)
+	assert.Contains(t, string(examples), "// Output: Hello, Ada!")
+
+	makefile, err := os.ReadFile(filepath.Join("go-money", "Makefile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "release-patch release-minor release-major: test")
+	assert.Contains(t, string(makefile), "bench:")
+	assert.NotContains(t, string(makefile), "MAIN_PKG", "libraries build no binary")
+
+	changelog, err := os.ReadFile(filepath.Join("go-money", "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(changelog), "## [Unreleased]")
+
+	spec, err := os.ReadFile(filepath.Join("go-money", "project.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), `type: "library"`)
+	assert.NotContains(t, string(spec), "entities:")
+}
+
+func TestCreateNewProject_LibraryRejectsDB(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir), "Failed to change to temp directory")
+
+	DBType = "postgres"
+	defer func() { DBType = "" }()
+
+	var out bytes.Buffer
+	assert.False(t, createNewProject("money", "", "library", &out))
+	assert.Contains(t, out.String(), `library projects take no database, but "postgres" is set`)
+	_, err = os.Stat("money")
+	assert.True(t, os.IsNotExist(err), "nothing is written for a rejected project")
+}
@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// baseColumns are the columns every generated model has, per dialect.
+var baseColumns = map[string][]string{
+	"postgres": {"id bigserial PRIMARY KEY", "created_at timestamptz", "updated_at timestamptz", "deleted_at timestamptz"},
+	"mysql":    {"id bigint unsigned AUTO_INCREMENT PRIMARY KEY", "created_at datetime(3) NULL", "updated_at datetime(3) NULL", "deleted_at datetime(3) NULL"},
+	"sqlite":   {"id integer PRIMARY KEY AUTOINCREMENT", "created_at datetime", "updated_at datetime", "deleted_at datetime"},
+}
+
+// foreignKeyTypes match the type of the id column they reference.
+var foreignKeyTypes = map[string]string{"postgres": "bigint", "mysql": "bigint unsigned", "sqlite": "integer"}
+
+// quotedDefaults are the field types whose default value is a SQL string.
+var quotedDefaults = map[string]bool{"string": true, "text": true, "uuid": true, "time": true}
+
+// quoteIdent quotes a table or column name, so that names such as order or
+// user, reserved words in SQL, can be used.
+func quoteIdent(dialect, name string) string {
+	if dialect == "mysql" {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// writeMigrations writes numbered up/down SQL migrations creating the table
+// of every entity kept in a SQL store, followed by the join tables of
+// many_to_many relations. Entities must be ordered parents first. With
+// several stores each one gets its own directory, as they are migrated
+// separately.
+func writeMigrations(projectDir string, stores []StoreData, entities []string, entityStore map[string]string,
+	entityFields map[string][]parser.Field, relations map[string]*RelationData) error {
+	for _, store := range stores {
+		dir := filepath.Join(projectDir, "migrations")
+		if len(stores) > 1 {
+			dir = filepath.Join(dir, store.Name)
+		}
+
+		n := 0
+		write := func(table, up string) error {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			n++
+			base := fmt.Sprintf("%04d_create_%s", n, table)
+			down := fmt.Sprintf("DROP TABLE %s;\n", quoteIdent(store.Dialect, table))
+
+			if err := os.WriteFile(filepath.Join(dir, base+".up.sql"), []byte(up), 0644); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dir, base+".down.sql"), []byte(down), 0644)
+		}
+
+		created := map[string]bool{}
+		for _, entity := range entities {
+			if entityStore[entity] != store.Name {
+				continue
+			}
+			table := tableName(entity)
+			up := createTableSQL(table, store.Dialect, entityFields[entity], relations[entity].ForeignKeys, created)
+			if err := write(table, up); err != nil {
+				return err
+			}
+			created[table] = true
+		}
+
+		for _, entity := range entities {
+			if entityStore[entity] != store.Name {
+				continue
+			}
+			for _, assoc := range relations[entity].Associations {
+				if assoc.Kind != parser.ManyToMany || created[assoc.JoinTable] {
+					continue
+				}
+				up := joinTableSQL(assoc.JoinTable, store.Dialect, entity, assoc.TargetLower)
+				if err := write(assoc.JoinTable, up); err != nil {
+					return err
+				}
+				created[assoc.JoinTable] = true
+			}
+		}
+	}
+	return nil
+}
+
+func createTableSQL(table, dialect string, fields []parser.Field, foreignKeys []ForeignKeyData, created map[string]bool) string {
+	q := func(name string) string { return quoteIdent(dialect, name) }
+	index := func(kind, column string) string {
+		return fmt.Sprintf("CREATE %s idx_%s_%s ON %s (%s);", kind, table, column, q(table), q(column))
+	}
+
+	columns := append([]string(nil), baseColumns[dialect]...)
+	indexes := []string{index("INDEX", "deleted_at")}
+	var constraints, notes []string
+
+	for _, fd := range fieldData(fields) {
+		col := q(fd.Column) + " " + parser.FieldTypes[fd.Spec.Type].SQL[dialect]
+		if !fd.Spec.Nullable {
+			col += " NOT NULL"
+		}
+		if def := fd.Spec.Default; def != "" {
+			if quotedDefaults[fd.Spec.Type] {
+				def = "'" + strings.ReplaceAll(def, "'", "''") + "'"
+			}
+			col += " DEFAULT " + def
+		}
+		columns = append(columns, col)
+
+		switch {
+		case fd.Spec.Unique:
+			indexes = append(indexes, index("UNIQUE INDEX", fd.Column))
+		case fd.Spec.Index:
+			indexes = append(indexes, index("INDEX", fd.Column))
+		}
+	}
+
+	for _, fk := range foreignKeys {
+		col := q(fk.Column()) + " " + foreignKeyTypes[dialect]
+		if fk.Required {
+			col += " NOT NULL"
+		}
+		columns = append(columns, col)
+		indexes = append(indexes, index("INDEX", fk.Column()))
+
+		parent := tableName(fk.ParentLower)
+		constraint := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (id)", q(fk.Column()), q(parent))
+		if parent != table && !created[parent] {
+			// Optional relations may point both ways; the table created
+			// first cannot reference the other one yet.
+			notes = append(notes, fmt.Sprintf("-- %s is created later, add when needed: %s\n", parent, constraint))
+			continue
+		}
+		constraints = append(constraints, constraint)
+	}
+	columns = append(columns, constraints...)
+
+	var b strings.Builder
+	b.WriteString(strings.Join(notes, ""))
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n    %s\n);\n\n", q(table), strings.Join(columns, ",\n    "))
+	b.WriteString(strings.Join(indexes, "\n"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func joinTableSQL(table, dialect, a, b string) string {
+	q := func(name string) string { return quoteIdent(dialect, name) }
+	colA, colB := q(naming.Snake(a)+"_id"), q(naming.Snake(b)+"_id")
+	typ := foreignKeyTypes[dialect]
+
+	return fmt.Sprintf(`CREATE TABLE %s (
+    %s %s NOT NULL,
+    %s %s NOT NULL,
+    PRIMARY KEY (%s, %s),
+    FOREIGN KEY (%s) REFERENCES %s (id),
+    FOREIGN KEY (%s) REFERENCES %s (id)
+);
+`, q(table), colA, typ, colB, typ, colA, colB, colA, q(tableName(a)), colB, q(tableName(b)))
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// modulePlaceholder is replaced by the module of the entity in the paths
+// of modular templates, e.g. internal/modules/modulename/handler.
+const modulePlaceholder = layout.ModulePlaceholder
+
+// ModuleData is a bounded context of a modular project as the templates
+// need it.
+type ModuleData struct {
+	Name     string
+	Entities []string // entity names, in generation order
+	Types    []string // Go types of the entities
+	Realtime bool     // whether any of its entities is realtime
+}
+
+// resolveModules groups the entities into the modules declared in
+// project.yaml, keeping the generation order within each module. Without
+// declared modules every entity is in parser.DefaultModule. The config must
+// have been validated, so that every entity is in exactly one module.
+func resolveModules(yamlConfig *parser.Config, entities []string) []ModuleData {
+	if yamlConfig == nil || len(yamlConfig.Modules) == 0 {
+		module := ModuleData{Name: parser.DefaultModule}
+		for _, entity := range entities {
+			module.Entities = append(module.Entities, entity)
+			module.Types = append(module.Types, entityType(entity))
+		}
+		return []ModuleData{module}
+	}
+
+	modules := make([]ModuleData, len(yamlConfig.Modules))
+	index := make(map[string]int, len(modules))
+	for i, module := range yamlConfig.Modules {
+		modules[i].Name = module.Name
+		index[module.Name] = i
+	}
+	for _, entity := range entities {
+		i := index[yamlConfig.ModuleOf(entity)]
+		modules[i].Entities = append(modules[i].Entities, entity)
+		modules[i].Types = append(modules[i].Types, entityType(entity))
+	}
+	return modules
+}
+
+// moduleOf returns the module of entity among modules.
+func moduleOf(modules []ModuleData, entity string) ModuleData {
+	for _, module := range modules {
+		for _, name := range module.Entities {
+			if name == entity {
+				return module
+			}
+		}
+	}
+	return ModuleData{}
+}
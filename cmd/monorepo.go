@@ -0,0 +1,344 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/compose"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"github.com/upsaurav12/bootstrap/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// servicesDir holds one module per service of a monorepo, and sharedDir
+// the module of the code they share.
+const (
+	servicesDir = "services"
+	sharedDir   = "pkg"
+)
+
+// firstServicePort is the port given to the first service that serves but
+// sets none; the next ones get the following free ports.
+const firstServicePort = 8080
+
+// specSchemaComment points editors at the JSON Schema of project.yaml.
+const specSchemaComment = "yaml-language-server: $schema=https://raw.githubusercontent.com/upsaurav12/bootstrap/main/schema/project.schema.json"
+
+// ServiceData is a service of a monorepo as the root templates and the
+// combined docker-compose.yml need it.
+type ServiceData struct {
+	Name string
+	// Dir is the module of the service, relative to the root.
+	Dir  string
+	Type string
+	Arch string
+	// Port is empty for types that run no server.
+	Port string
+	// Main is the package of the service's binary, relative to Dir.
+	Main        string
+	Healthcheck []string
+	Stores      []StoreData
+	Features    FeaturesData
+	// Spec is the project spec the service is generated from.
+	Spec *parser.Config
+}
+
+// resolveServices turns the services of a monorepo spec into the specs of
+// their projects, with the free ports from firstServicePort given to
+// servers that set none.
+func resolveServices(config *parser.Config) ([]ServiceData, error) {
+	used := map[int]bool{}
+	for _, service := range config.Services {
+		used[service.Port] = true
+	}
+	next := firstServicePort
+
+	services := make([]ServiceData, 0, len(config.Services))
+	for _, service := range config.Services {
+		spec := service.Config(config)
+		t := layout.TypeRegistory[first(spec.Project.Type, layout.DefaultType)]
+		if spec.Project.Port == 0 && t.Healthcheck != nil {
+			for used[next] {
+				next++
+			}
+			spec.Project.Port = next
+			used[next] = true
+		}
+
+		settings, err := resolveProject(spec, ProjectSettings{})
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", service.Name, err)
+		}
+		entities := spec.EntityNames()
+		if t.Entities && len(entities) == 0 {
+			entities = []string{"user"}
+		}
+		stores, _, err := resolveStores(spec, settings.DB, entities)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", service.Name, err)
+		}
+		features, err := resolveFeatures(spec, nil)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", service.Name, err)
+		}
+		// The spec written to the service pins what it was generated with.
+		spec.Project.Type, spec.Project.Arch, spec.Project.Router = settings.Type, settings.Arch, settings.Router
+
+		services = append(services, ServiceData{
+			Name:        service.Name,
+			Dir:         path.Join(servicesDir, service.Name),
+			Type:        settings.Type,
+			Arch:        settings.Arch,
+			Port:        settings.Port,
+			Main:        settings.Layout.Main,
+			Healthcheck: t.Healthcheck,
+			Stores:      stores,
+			Features:    features,
+			Spec:        spec,
+		})
+	}
+	return services, nil
+}
+
+// createMonorepo generates the monorepo of a spec that declares services:
+// a module per service under services/, the shared module in pkg/, a
+// go.work tying them together, and the Makefile and docker-compose.yml
+// that drive them all.
+func createMonorepo(config *parser.Config, flags ProjectSettings, out io.Writer) bool {
+	// Flags would apply to every service at once; the spec sets them per
+	// service instead.
+	for _, flag := range []struct{ name, value string }{
+		{"type", flags.Type}, {"arch", flags.Arch}, {"router", flags.Router}, {"port", flags.Port}, {"db", flags.DB},
+	} {
+		if flag.value != "" {
+			fmt.Fprintf(out, "Error: --%s cannot be used with a spec that declares services; set it on each service\n", flag.name)
+			return false
+		}
+	}
+	if len(featureFlags) > 0 {
+		fmt.Fprintln(out, "Error: --feature cannot be used with a spec that declares services; set it on each service")
+		return false
+	}
+
+	name := first(flags.Name, config.Project.Name)
+	if name == "" {
+		fmt.Fprintln(out, "Error: project name is required")
+		return false
+	}
+	location := first(flags.Location, config.Project.Location, ".")
+	rootDir := filepath.Join(location, name)
+
+	services, err := resolveServices(config)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return false
+	}
+
+	if err := os.MkdirAll(location, 0755); err != nil {
+		fmt.Fprintf(out, "Error creating directory %s: %v\n", location, err)
+		return false
+	}
+	if err := os.Mkdir(rootDir, 0755); err != nil {
+		fmt.Fprintf(out, "Error creating directory %s: %v\n", rootDir, err)
+		return false
+	}
+
+	for _, service := range services {
+		if !generateService(rootDir, YAMLPath, service, out) {
+			return false
+		}
+	}
+
+	if err := writeMonorepo(rootDir, name, services); err != nil {
+		fmt.Fprintf(out, "Error writing the monorepo files: %v\n", err)
+		return false
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "go.work"), []byte(goWork(services)), 0644); err != nil {
+		fmt.Fprintf(out, "Error writing go.work: %v\n", err)
+		return false
+	}
+	if err := copyProjectYAML(YAMLPath, rootDir); err != nil {
+		fmt.Fprintf(out, "warning: could not copy project.yaml: %v\n", err)
+	}
+
+	fmt.Fprintf(out, "✓ Created monorepo '%s' with %d services\n", name, len(services))
+	return true
+}
+
+// generateService writes the module of one service under services/ of the
+// monorepo at rootDir, with its own spec as its project.yaml. specPath is
+// the root spec, which seed files are relative to.
+func generateService(rootDir, specPath string, service ServiceData, out io.Writer) bool {
+	dir := filepath.Join(rootDir, servicesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(out, "Error creating directory %s: %v\n", dir, err)
+		return false
+	}
+	if _, ok := generateProject(service.Spec, specPath, ProjectSettings{Location: dir}, out); !ok {
+		return false
+	}
+	if err := writeServiceSpec(filepath.Join(rootDir, filepath.FromSlash(service.Dir)), service.Spec); err != nil {
+		fmt.Fprintf(out, "Error writing the project.yaml of %s: %v\n", service.Name, err)
+		return false
+	}
+	return true
+}
+
+// writeServiceSpec replaces the project.yaml of a service module, a copy
+// of the root spec, with the spec of the service alone.
+func writeServiceSpec(dir string, spec *parser.Config) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", specSchemaComment)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(spec); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "project.yaml"), buf.Bytes(), 0644)
+}
+
+// writeMonorepo renders the root files of a new monorepo: the shared
+// module, the README and the Dockerfile of the services, then those that
+// list the services.
+func writeMonorepo(rootDir, name string, services []ServiceData) error {
+	if err := renderTemplateDir("monorepo", rootDir, TemplateData{ModuleName: name, Services: services, GoVersion: goDirective(goWork(services))}); err != nil {
+		return err
+	}
+	return updateMonorepo(rootDir, name, services)
+}
+
+// updateMonorepo rewrites the root files that list the services: the
+// Makefile, the compose file and the .env it reads.
+func updateMonorepo(rootDir, name string, services []ServiceData) error {
+	const makefile = "monorepo/Makefile.tmpl"
+	content, err := templates.FS.ReadFile(makefile)
+	if err != nil {
+		return err
+	}
+	data := TemplateData{ModuleName: name, Services: services}
+	if err := writeSingle(data, "Makefile", makefile, content, rootDir); err != nil {
+		return err
+	}
+	if err := writeMonorepoEnv(rootDir, services); err != nil {
+		return err
+	}
+	return writeMonorepoCompose(rootDir, services)
+}
+
+// goWork lists the shared module and the module of every service.
+func goWork(services []ServiceData) string {
+	var b strings.Builder
+	b.WriteString("go 1.23.0\n\nuse (\n\t./" + sharedDir + "\n")
+	for _, service := range services {
+		b.WriteString("\t./" + service.Dir + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// addWorkUse adds dir to the use directives of a go.work file, keeping the
+// rest of it as it is.
+func addWorkUse(content, dir string) string {
+	use := "./" + dir
+	lines := strings.Split(content, "\n")
+	inBlock := false
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[0] == "use" && fields[1] == "(":
+			inBlock = true
+		case inBlock && len(fields) > 0 && fields[0] == ")":
+			lines = append(lines[:i], append([]string{"\t" + use}, lines[i:]...)...)
+			return strings.Join(lines, "\n")
+		}
+	}
+	return strings.TrimRight(content, "\n") + "\n\nuse " + use + "\n"
+}
+
+// writeMonorepoEnv writes the .env docker compose reads at the root: the
+// settings of the datastores, which the services share the containers of,
+// taken from the first service that has them.
+func writeMonorepoEnv(rootDir string, services []ServiceData) error {
+	var b strings.Builder
+	b.WriteString("# Read by docker compose for the databases the services share.\n")
+	b.WriteString("# Each service reads its own settings from services/<name>/.env.\n")
+	seen := map[string]bool{}
+	for _, service := range services {
+		for _, setting := range resolveSettings("", service.Stores, FeaturesData{}) {
+			if !seen[setting.Key] {
+				seen[setting.Key] = true
+				fmt.Fprintf(&b, "%s=%s\n", setting.Key, envQuote(setting.Local))
+			}
+		}
+	}
+	return os.WriteFile(filepath.Join(rootDir, ".env"), []byte(b.String()), 0644)
+}
+
+// writeMonorepoCompose assembles the docker-compose.yml of a monorepo: an
+// app per service that runs a server, built from the root so that it sees
+// the shared module, and the backing services they use, once each.
+func writeMonorepoCompose(rootDir string, services []ServiceData) error {
+	var files []*compose.File
+	for _, service := range services {
+		if service.Healthcheck == nil {
+			continue
+		}
+
+		healthcheck := make([]string, len(service.Healthcheck))
+		for i, arg := range service.Healthcheck {
+			healthcheck[i] = strings.ReplaceAll(arg, "${PORT}", service.Port)
+		}
+		extras := service.Features.Services()
+		app := appFragment(healthcheck, service.Stores, extras)
+		app.Name = service.Name
+		app.Service.Build = compose.BuildSpec{
+			Context:    ".",
+			Dockerfile: "Dockerfile",
+			Args:       map[string]string{"SERVICE": service.Name, "MAIN": service.Main},
+		}
+		app.Service.EnvFile = []string{service.Dir + "/.env"}
+		app.Service.Ports = []string{service.Port + ":" + service.Port}
+
+		// Embedded databases get a volume per service, as the services
+		// would otherwise share the file.
+		prefix := naming.Snake(service.Name) + "_"
+		for i, volume := range app.Volumes {
+			app.Volumes[i] = prefix + volume
+		}
+		for i, mount := range app.Service.Volumes {
+			app.Service.Volumes[i] = prefix + mount
+		}
+
+		file, err := compose.Build(app, composeDeps(service.Stores, extras)...)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", service.Name, err)
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	file, err := compose.Combine(files...)
+	if err != nil {
+		return err
+	}
+	content, err := file.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(rootDir, "docker-compose.yml"), content, 0644)
+}
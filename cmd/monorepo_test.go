@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upsaurav12/bootstrap/pkg/compose"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+const monorepoSpec = `project:
+  name: shop
+  router: chi
+services:
+  - name: users
+    db: postgres
+    entities: [user]
+  - name: orders
+    port: 9000
+    db: sqlite
+    entities: [order]
+  - name: mailer
+    type: worker
+    jobs: [send_email]
+  - name: admin
+    type: cli
+`
+
+func TestCreateMonorepo(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("project.yaml", []byte(monorepoSpec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	require.True(t, createNewProject("", "", "", &out), out.String())
+	assert.Contains(t, out.String(), "✓ Created monorepo 'shop' with 4 services\n")
+
+	for _, file := range []string{
+		"go.work",
+		"Makefile",
+		"Dockerfile",
+		"README.md",
+		"docker-compose.yml",
+		".env",
+		"project.yaml",
+		"pkg/go.mod",
+		"pkg/doc.go",
+		"services/users/go.mod",
+		"services/users/internal/handler/user_handler.go",
+		"services/orders/internal/handler/order_handler.go",
+		"services/mailer/internal/jobs/send_email.go",
+		"services/admin/cmd/root.go",
+	} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+
+	work, err := os.ReadFile(filepath.Join("shop", "go.work"))
+	require.NoError(t, err)
+	assert.Equal(t, "go 1.23.0\n\nuse (\n\t./pkg\n\t./services/users\n\t./services/orders\n\t./services/mailer\n\t./services/admin\n)\n", string(work))
+
+	shared, err := os.ReadFile(filepath.Join("shop", "pkg/go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(shared), "module shop/pkg\n")
+
+	makefile, err := os.ReadFile(filepath.Join("shop", "Makefile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "SERVICES := users orders mailer admin\n")
+	assert.Contains(t, string(makefile), "\t@$(GO) work use\n\t@$(GO) work sync\n\t@$(SYNC_DOCKERFILE)\n")
+
+	dockerfile, err := os.ReadFile(filepath.Join("shop", "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(dockerfile), "FROM golang:1.23-alpine AS build", "the builder image follows go.work")
+
+	// Each service gets its own spec, with the ports that were given out.
+	spec, err := parser.ReadYAML(filepath.Join("shop", "services/users/project.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, parser.Project{Name: "users", Type: "rest", Arch: "clean", Port: 8080, Database: "postgres", Router: "chi"}, spec.Project)
+	spec, err = parser.ReadYAML(filepath.Join("shop", "services/mailer/project.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, 8081, spec.Project.Port)
+
+	content, err := os.ReadFile(filepath.Join("shop", "docker-compose.yml"))
+	require.NoError(t, err)
+	var file compose.File
+	require.NoError(t, yaml.Unmarshal(content, &file))
+	assert.ElementsMatch(t, []string{"users", "orders", "mailer", "postgres_bp"}, keys(file.Services), "cli services run no container")
+	assert.Equal(t, compose.BuildSpec{Context: ".", Dockerfile: "Dockerfile", Args: map[string]string{"SERVICE": "orders", "MAIN": "./cmd"}},
+		file.Services["orders"].Build)
+	assert.Equal(t, []string{"9000:9000"}, file.Services["orders"].Ports)
+	assert.Equal(t, []string{"services/orders/.env"}, file.Services["orders"].EnvFile)
+	assert.Equal(t, []string{"orders_sqlite_volume_bp:/data"}, file.Services["orders"].Volumes)
+	assert.Contains(t, file.Services["users"].Healthcheck.Test, "http://localhost:8080/health")
+
+	env, err := os.ReadFile(filepath.Join("shop", ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "GONE_DB_USERNAME=example_username\n")
+	assert.NotContains(t, string(env), "PORT=8080")
+}
+
+func TestCreateMonorepo_RejectsFlags(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("project.yaml", []byte(monorepoSpec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.False(t, createNewProject("", "gin", "", &out))
+	assert.Contains(t, out.String(), "Error: --router cannot be used with a spec that declares services")
+	_, err = os.Stat("shop")
+	assert.True(t, os.IsNotExist(err), "nothing is written")
+}
+
+func TestAddService(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("project.yaml", []byte(monorepoSpec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	require.True(t, createNewProject("", "", "", &out), out.String())
+	YAMLPath, DBType, Entities = "", "", nil
+
+	out.Reset()
+	service := parser.Service{Name: "billing", Database: "postgres", Entities: []parser.Entity{{Name: "invoice"}}}
+	require.NoError(t, addService("shop", service, &out))
+	assert.Contains(t, out.String(), "✓ Added service 'billing' in services/billing\n")
+
+	_, err = os.Stat(filepath.Join("shop", "services/billing/internal/handler/invoice_handler.go"))
+	assert.NoError(t, err)
+
+	config, err := parser.ReadYAML(filepath.Join("shop", "project.yaml"))
+	require.NoError(t, err)
+	added, ok := config.Service("billing")
+	assert.True(t, ok)
+	assert.Equal(t, service, added)
+
+	work, err := os.ReadFile(filepath.Join("shop", "go.work"))
+	require.NoError(t, err)
+	assert.Contains(t, string(work), "\t./services/admin\n\t./services/billing\n)\n")
+
+	makefile, err := os.ReadFile(filepath.Join("shop", "Makefile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "SERVICES := users orders mailer admin billing\n")
+
+	// The first port no other service uses.
+	content, err := os.ReadFile(filepath.Join("shop", "docker-compose.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "  billing:\n")
+	assert.Contains(t, string(content), "8082:8082")
+
+	assert.EqualError(t, addService("shop", service, &out), `service "billing" already exists`)
+}
+
+func TestAddWorkUse(t *testing.T) {
+	tests := []struct {
+		name, work, want string
+	}{
+		{"block", "go 1.23.0\n\nuse (\n\t./pkg\n)\n", "go 1.23.0\n\nuse (\n\t./pkg\n\t./services/users\n)\n"},
+		{"single", "go 1.23.0\n\nuse ./pkg\n", "go 1.23.0\n\nuse ./pkg\n\nuse ./services/users\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, addWorkUse(tt.work, "services/users"))
+		})
+	}
+}
+
+func keys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -7,11 +7,13 @@ Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
 	"io"
 	"io/fs"
-	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -21,7 +23,10 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/upsaurav12/bootstrap/pkg/addons"
 	"github.com/upsaurav12/bootstrap/pkg/framework"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/naming"
 	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"github.com/upsaurav12/bootstrap/pkg/rpcgen"
 	"github.com/upsaurav12/bootstrap/templates"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -168,11 +173,23 @@ func (m wizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case stepName:
 				m.input.Name = m.text.Value()
 				m.step = stepType
-				m.list = newList("Project type", []string{"rest"})
+				m.list = newList("Project type", layout.Types())
 				return m, nil
 
 			case stepType:
 				m.input.Type = m.list.SelectedItem().(item).Title()
+				if t := layout.TypeRegistory[m.input.Type]; t.Healthcheck == nil && !t.Databases {
+					// Tools that run no server and keep no data have
+					// nothing more to set up.
+					m.step = stepConfirm
+					return m, nil
+				}
+				if !layout.TypeRegistory[m.input.Type].HTTP {
+					// Only HTTP projects are served through a router.
+					m.step = stepPort
+					m.text = newTextInput("")
+					return m, nil
+				}
 				m.step = stepRouter
 				m.list = newList("Router", []string{"gin", "chi", "echo"})
 				return m, nil
@@ -193,7 +210,7 @@ func (m wizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.input.Port = port
 				m.step = stepDB
-				m.list = newList("Database", []string{"postgres", "mysql", "mongo"})
+				m.list = newList("Database", addons.Databases())
 				return m, nil
 
 			case stepDB:
@@ -274,14 +291,13 @@ func (m wizardModel) View() string {
 		)
 
 	case stepConfirm:
-		summary := fmt.Sprintf(
-			"Project:  %s\nType:     %s\nRouter:   %s\nPort:     %s\nDatabase: %s",
-			m.input.Name,
-			m.input.Type,
-			m.input.Router,
-			m.input.Port,
-			m.input.DB,
-		)
+		summary := fmt.Sprintf("Project:  %s\nType:     %s\n", m.input.Name, m.input.Type)
+		if m.input.Router != "" {
+			summary += fmt.Sprintf("Router:   %s\n", m.input.Router)
+		}
+		if m.input.Port != "" {
+			summary += fmt.Sprintf("Port:     %s\nDatabase: %s", m.input.Port, m.input.DB)
+		}
 
 		return renderStep(
 			m,
@@ -311,7 +327,8 @@ func copyProjectYAML(srcPath, destDir string) error {
 		return nil // nothing to copy
 	}
 
-	content, err := os.ReadFile(srcPath)
+	// Specs that extend others are copied merged, as their bases are not.
+	content, err := parser.ResolveYAML(srcPath)
 	if err != nil {
 		return err
 	}
@@ -365,7 +382,7 @@ var newCmd = &cobra.Command{
 		if len(args) < 1 && YAMLPath != "" {
 			yamlConfig, err := parser.ReadYAML(YAMLPath)
 			if err != nil {
-				fmt.Println("error while creating project using yaml: ", err)
+				fmt.Fprintf(cmd.OutOrStdout(), "Error in project spec:\n%v\n", err)
 				return
 			}
 
@@ -390,10 +407,13 @@ var newCmd = &cobra.Command{
 var projectType string
 var projectPort string
 var projectRouter string
+var projectArch string
+var projectLocation string
 var DBType string
 var YAMLPath string
 var Entitys string
 var Entities []string
+var featureFlags []string
 var yamlFile string
 
 type TemplateData struct {
@@ -413,7 +433,6 @@ type TemplateData struct {
 	LowerEntity   string
 	OtherImports  string
 	UpperEntity   []string
-	ApiGroup      func(entity string, get string, lowerentity string) string
 	Get           string
 	FullContext   string
 	ToTheClient   string
@@ -421,11 +440,7 @@ type TemplateData struct {
 	ImportHandler string
 	ImportRouter  string
 	ServiceName   string
-	Image         string
-	Environment   string
 	Port          string
-	Volume        string
-	VolumeName    string
 	DBName        string
 	DBEnvPrefix   string
 	Import        string
@@ -434,6 +449,81 @@ type TemplateData struct {
 	Returnable    string
 	ReturnKeyword string
 	HTTPHandler   string
+	SeedJSON      string
+	Seeds         map[string]string
+	Store         string
+	Stores        []StoreData
+	EntityStore   map[string]string
+	Drivers       map[string]bool
+	PathParam     string
+	QueryParam    string
+	WriteJSON     string
+	NoContent     string
+	Route         func(method, path, handler string) string
+	Mount         func(path, handler string) string
+	Buffered      bool
+	ReturnRouter  string
+	Group         string
+	MountGroup    string
+	HelperImports string
+	Helpers       string
+	EntityFields  map[string][]parser.Field
+	Fields        []FieldData
+	ModelImports  []string
+	DTOImports    []string
+	Relations     map[string]*RelationData
+	ForeignKeys   []ForeignKeyData
+	Associations  []AssociationData
+	ManyToMany    []AssociationData
+	Patterns      []PatternData // regex rules of the entity's fields
+	UniqueFields  []FieldData   // fields of the entity with a unique index
+	Operations    []OperationData
+	// TestImports are the packages the tests of the entity need for the
+	// Distinct values of its unique fields.
+	TestImports []string
+	// EntityOperations are the custom_logic operations of every entity.
+	EntityOperations map[string][]OperationData
+	Features         FeaturesData
+	ProjectType      string
+	Arch             string
+	Layout           layout.ArchConfig
+	// Settings are the environment variables the app reads, and Profiles
+	// the deployment environments declared in project.yaml.
+	Settings []SettingData
+	Profiles []ProfileData
+	// Modules are the bounded contexts of modular projects, and Module the
+	// one of the entity being rendered.
+	Modules []ModuleData
+	Module  ModuleData
+	// ProtoFields are the fields of the entity's messages, ProtoImports
+	// the files its service file imports, and ProtoHelpers the conversion
+	// helpers the gRPC servers of all entities call.
+	ProtoFields  []ProtoFieldData
+	ProtoImports []string
+	ProtoHelpers map[string]bool
+	// GraphQLFields are the fields of the entity's object and input types,
+	// and GraphQLHelpers the scalars and conversion helpers the resolvers
+	// of all entities use.
+	GraphQLFields  []GraphQLFieldData
+	GraphQLHelpers map[string]bool
+	// Commands are the subcommands of CLI projects, and Command the one
+	// being rendered.
+	Commands []CommandData
+	Command  CommandData
+	// Jobs are the jobs of worker projects, and Job the one being rendered.
+	Jobs []JobData
+	Job  JobData
+	// Services are the services of a monorepo, for its root files.
+	Services []ServiceData
+	// Realtime are the entities whose changes are streamed, and Streamed
+	// whether the entity being rendered is one of them.
+	Realtime map[string]bool
+	Streamed bool
+	// Packages are the packages of the layers of the entity being rendered.
+	Packages layout.Packages
+	// GoVersion is the major.minor release of the go directive the project
+	// starts with, which tags the builder image of its Dockerfile.
+	GoVersion string
 }
 
 type TemplateJob struct {
@@ -446,13 +536,16 @@ func init() {
 	rootCmd.AddCommand(newCmd)
 
 	// Define the --template flag for this command
-	newCmd.Flags().StringVar(&projectType, "type", "", "type of the project")
+	newCmd.Flags().StringVar(&projectType, "type", "", "type of the project ("+strings.Join(layout.Types(), ", ")+"; default "+layout.DefaultType+")")
+	newCmd.Flags().StringVar(&projectArch, "arch", "", "architecture of the project, e.g. clean")
+	newCmd.Flags().StringVar(&projectLocation, "location", "", "directory to create the project in (default: current directory)")
 	newCmd.Flags().StringVar(&projectPort, "port", "", "port of the project")
-	newCmd.Flags().StringVar(&projectRouter, "router", "", "router of the project")
+	newCmd.Flags().StringVar(&projectRouter, "router", "", "router of the project (default "+defaultRouter+")")
 	newCmd.Flags().StringVar(&DBType, "db", "", "data type of the project")
 	newCmd.Flags().StringVar(&YAMLPath, "yaml", "", "yaml file path")
 	newCmd.Flags().StringVar(&Entitys, "entity", "", "entity")
 	newCmd.Flags().StringSliceVar(&Entities, "entities", nil, "different entities")
+	newCmd.Flags().StringArrayVar(&featureFlags, "feature", nil, "enable a feature as kind[=provider], e.g. cache=redis, queue=nats, auth=jwt")
 	newCmd.Flags().Bool("interactive", false, "run interactive project setup")
 
 }
@@ -477,7 +570,6 @@ func buildTemplateData(projectName string,
 		JSON:          frameworkConfig.JSON,
 		LowerEntity:   Entitys,
 		OtherImports:  frameworkConfig.OtherImports,
-		ApiGroup:      frameworkConfig.ApiGroup,
 		Get:           frameworkConfig.Get,
 		FullContext:   frameworkConfig.FullContext,
 		ToTheClient:   frameworkConfig.ToTheClient,
@@ -507,7 +599,6 @@ func buildTemplateData(projectName string,
 			LowerEntity:   Entitys,
 			UpperEntity:   nil,
 			OtherImports:  frameworkConfig.OtherImports,
-			ApiGroup:      frameworkConfig.ApiGroup,
 			Get:           frameworkConfig.Get,
 			FullContext:   frameworkConfig.FullContext,
 			ToTheClient:   frameworkConfig.ToTheClient,
@@ -517,10 +608,23 @@ func buildTemplateData(projectName string,
 			Returnable:    frameworkConfig.Returnable,
 			ReturnKeyword: frameworkConfig.ReturnKeyword,
 			HTTPHandler:   frameworkConfig.HTTPHandler,
-			Entities:      yamlConfig.Entities,
+			Entities:      yamlConfig.EntityNames(),
 		}
 	}
 
+	data.PathParam = frameworkConfig.PathParam
+	data.QueryParam = frameworkConfig.QueryParam
+	data.WriteJSON = frameworkConfig.WriteJSON
+	data.NoContent = frameworkConfig.NoContent
+	data.Route = frameworkConfig.Route
+	data.Mount = frameworkConfig.Mount
+	data.Buffered = frameworkConfig.Buffered
+	data.ReturnRouter = frameworkConfig.ReturnRouter
+	data.Group = frameworkConfig.Group
+	data.MountGroup = frameworkConfig.MountGroup
+	data.HelperImports = frameworkConfig.HelperImports
+	data.Helpers = frameworkConfig.Helpers
+
 	if dbConfig != nil {
 		data.ServiceName = dbConfig.ServiceName
 		data.DBName = dbConfig.DBName
@@ -529,15 +633,40 @@ func buildTemplateData(projectName string,
 		data.DSN = dbConfig.DSN
 		data.Driver = dbConfig.Driver
 		data.Import = dbConfig.Import
-		data.Image = dbConfig.Image
-		data.Environment = dbConfig.Environment
-		data.Volume = dbConfig.Volume
-		data.VolumeName = dbConfig.VolumeName
 	}
 
 	return data
 }
 
+// goVersion returns the release of the go directive in the go.mod template
+// of the first of dirs that has one.
+func goVersion(dirs []string) string {
+	for _, dir := range dirs {
+		content, err := templates.FS.ReadFile(dir + "/go.mod.tmpl")
+		if err == nil {
+			return goDirective(string(content))
+		}
+	}
+	return ""
+}
+
+// goDirective returns the major.minor release of the go directive of a
+// go.mod or go.work file, as golang images are tagged, or "" if it has none.
+func goDirective(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "go" {
+			continue
+		}
+		parts := strings.SplitN(fields[1], ".", 3)
+		if len(parts) < 2 {
+			return fields[1]
+		}
+		return parts[0] + "." + parts[1]
+	}
+	return ""
+}
+
 func returnUppercase(entity string) string {
 	if entity == "" {
 		return ""
@@ -549,70 +678,156 @@ func returnUppercase(entity string) string {
 	return string(runes)
 }
 
-func createNewProject(projectName, projectRouter, template string, out io.Writer) {
-	err := os.Mkdir(projectName, 0755)
-	if err != nil {
-		log.Fatalf("error occured while creating a new project %s: ", err)
+// createNewProject generates a project from the flags and the spec, writing
+// progress and errors to out, and reports whether it succeeded. Specs that
+// declare services are generated as a monorepo.
+func createNewProject(projectName, projectRouter, template string, out io.Writer) bool {
+	// The spec is validated before anything is written.
+	var yamlConfig *parser.Config
+	if YAMLPath != "" {
+		var err error
+		yamlConfig, err = parser.ReadYAML(YAMLPath)
+		if err != nil {
+			fmt.Fprintf(out, "Error in project spec:\n%v\n", err)
+			return false
+		}
 	}
-	err = os.MkdirAll(filepath.Join(projectName, "internal"), 0755)
 
-	if err != nil {
-		log.Fatalf("error occured while creating a new project %s: ", projectName)
+	flags := ProjectSettings{
+		Name:     projectName,
+		Location: projectLocation,
+		Type:     template,
+		Arch:     projectArch,
+		Router:   projectRouter,
+		Port:     projectPort,
+		DB:       DBType,
 	}
-
-	// ✅ COPY project.yaml if provided
-	if err := copyProjectYAML(YAMLPath, projectName); err != nil {
-		fmt.Fprintf(out, "warning: could not copy project.yaml: %v\n", err)
+	if yamlConfig != nil && len(yamlConfig.Services) > 0 {
+		return createMonorepo(yamlConfig, flags, out)
 	}
+	_, ok := generateProject(yamlConfig, YAMLPath, flags, out)
+	return ok
+}
 
-	// Prepare configs
+// generateProject writes the project of yamlConfig, read from specPath, or
+// of the flags alone when yamlConfig is nil. It returns the data the
+// project was rendered with.
+func generateProject(yamlConfig *parser.Config, specPath string, flags ProjectSettings, out io.Writer) (TemplateData, bool) {
+	settings, err := resolveProject(yamlConfig, flags)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return TemplateData{}, false
+	}
+	var pinned []setting
+	if yamlConfig != nil {
+		pinned = overriddenSettings(yamlConfig.Project, flags)
+	}
+	projectName := settings.Name
+	projectDir := settings.Dir()
+	DBType = settings.DB
+	projectKind := layout.TypeRegistory[settings.Type]
 
-	var frameworkConfig framework.FrameworkConfig
-	frameworkConfig = framework.FrameworkRegistory[projectRouter]
+	frameworkConfig := framework.FrameworkRegistory[settings.Router]
 
 	var dbConfig *addons.DbAddOneConfig
+	if DBType != "" {
+		cfg := addons.DbRegistory[DBType]
+		dbConfig = &cfg
+	}
 
-	jobs := []TemplateJob{
-		{"common", projectName},
-		{"rest/clean", projectName},
+	if yamlConfig != nil {
+		Entities = yamlConfig.EntityNames()
 	}
 
-	var data TemplateData
+	// Projects always have at least one entity to wire end to end, unless
+	// their type generates none.
+	if !projectKind.Entities {
+		if len(Entities) > 0 {
+			fmt.Fprintf(out, "Error: %s projects take no entities\n", settings.Type)
+			return TemplateData{}, false
+		}
+	} else if len(Entities) == 0 {
+		Entities = []string{"user"}
+	}
+	// Names given with --entities or the wizard have not been checked yet.
+	if err := parser.CheckEntityNames(Entities); err != nil {
+		fmt.Fprintf(out, "Error in entities: %v\n", err)
+		return TemplateData{}, false
+	}
 
-	var yamlConfig *parser.Config
+	entityFields := make(map[string][]parser.Field, len(Entities))
+	for _, entity := range Entities {
+		fields := declaredFields(yamlConfig, entity)
+		spec := parser.Entity{Name: entity, Fields: fields}
+		if err := spec.CheckFields(); err != nil {
+			fmt.Fprintf(out, "Error in entity fields: %v\n", err)
+			return TemplateData{}, false
+		}
+		entityFields[entity] = fields
+	}
 
-	if YAMLPath != "" {
-		var err error
-		yamlConfig, err = parser.ReadYAML(YAMLPath)
-		if err != nil {
-			fmt.Printf("error while reading yaml file: %s", err)
-			return
+	if yamlConfig != nil {
+		if err := yamlConfig.CheckRelations(); err != nil {
+			fmt.Fprintf(out, "Error in entity relations: %v\n", err)
+			return TemplateData{}, false
 		}
 	}
+	relations := resolveRelations(yamlConfig, Entities)
+	Entities = orderEntities(Entities, relations)
 
-	if DBType != "" {
-		cfg := addons.DbRegistory[DBType]
-		dbConfig = &cfg
+	stores, entityStore, err := resolveStores(yamlConfig, DBType, Entities)
+	if err != nil {
+		fmt.Fprintf(out, "Error resolving datastores: %v\n", err)
+		return TemplateData{}, false
 	}
-	if DBType == "" && yamlConfig != nil {
-		DBType = yamlConfig.Project.Database
-		cfg := addons.DbRegistory[DBType]
-		dbConfig = &cfg
+	if err := checkRelationStores(Entities, relations, entityStore); err != nil {
+		fmt.Fprintf(out, "Error in entity relations: %v\n", err)
+		return TemplateData{}, false
 	}
 
-	if yamlConfig != nil {
-
-		frameworkConfig = framework.FrameworkRegistory[yamlConfig.Project.Router]
-		Entities = yamlConfig.Entities
+	features, err := resolveFeatures(yamlConfig, featureFlags)
+	if err != nil {
+		fmt.Fprintf(out, "Error in features: %v\n", err)
+		return TemplateData{}, false
+	}
+	if features.Any() && !projectKind.HTTP {
+		fmt.Fprintf(out, "Error in features: %s projects do not support features yet\n", settings.Type)
+		return TemplateData{}, false
+	}
+	realtime := resolveRealtime(yamlConfig, Entities)
+	if len(realtime) > 0 && !projectKind.Realtime {
+		fmt.Fprintf(out, "Error: %s projects take no realtime entities\n", settings.Type)
+		return TemplateData{}, false
+	}
 
-		if yamlConfig.Project.Database != "" {
+	seeds := map[string]string{}
+	if yamlConfig != nil {
+		var err error
+		seeds, err = seedData(yamlConfig, filepath.Dir(specPath))
+		if err != nil {
+			fmt.Fprintf(out, "Error loading seeds: %v\n", err)
+			return TemplateData{}, false
+		}
+	}
 
-			cfg := addons.DbRegistory[yamlConfig.Project.Database]
-			dbConfig = &cfg
+	fmt.Fprint(out, settings.summary(stores, features))
 
-			DBType = yamlConfig.Project.Database
+	if err := os.MkdirAll(settings.Location, 0755); err != nil {
+		fmt.Fprintf(out, "Error creating directory %s: %v\n", settings.Location, err)
+		return TemplateData{}, false
+	}
+	if err := os.Mkdir(projectDir, 0755); err != nil {
+		fmt.Fprintf(out, "Error creating directory %s: %v\n", projectDir, err)
+		return TemplateData{}, false
+	}
+	if err := os.MkdirAll(filepath.Join(projectDir, "internal"), 0755); err != nil {
+		fmt.Fprintf(out, "Error creating directory %s: %v\n", projectDir, err)
+		return TemplateData{}, false
+	}
 
-		}
+	jobs := []TemplateJob{{"common", projectDir}}
+	for _, dir := range settings.Layout.TemplateDirs {
+		jobs = append(jobs, TemplateJob{dir, projectDir})
 	}
 
 	var uppercase []string
@@ -623,37 +838,118 @@ func createNewProject(projectName, projectRouter, template string, out io.Writer
 		uppercase = append(uppercase, u)
 	}
 
-	data = buildTemplateData(
+	data := buildTemplateData(
 		projectName,
-		projectPort,
+		settings.Port,
 		frameworkConfig,
 		dbConfig,
 		yamlConfig, uppercase)
 
 	data.UpperEntity = uppercase
+	data.Entities = Entities
+	data.Seeds = seeds
+	data.Stores = stores
+	data.EntityStore = entityStore
+	data.Drivers = storeDialects(stores)
+	data.EntityFields = entityFields
+	data.Relations = relations
+	data.EntityOperations = resolveOperations(yamlConfig, Entities)
+	data.Features = features
+	data.ProjectType = settings.Type
+	data.Arch = settings.Arch
+	data.Layout = settings.Layout
+	data.Packages = settings.Layout.Packages
+	data.GoVersion = goVersion(settings.Layout.TemplateDirs)
+	data.Settings = resolveSettings(settings.Port, stores, features)
+	if projectKind.Jobs {
+		data.Settings = append(data.Settings, workerSettings()...)
+	}
+	data.Realtime = realtime
+	if len(data.Realtime) > 0 {
+		data.Settings = append(data.Settings, realtimeSettings()...)
+	}
+	data.Profiles = resolveProfiles(yamlConfig, data.Settings)
+	data.Modules = resolveModules(yamlConfig, Entities)
+	markRealtimeModules(data.Modules, data.Realtime)
+	if settings.Layout.ProtoDir != "" {
+		fields := make(map[string][]FieldData, len(Entities))
+		for _, entity := range Entities {
+			fields[entity] = entityFieldData(entity, entityFields[entity], relations[entity])
+		}
+		data.ProtoHelpers = protoHelpers(Entities, fields)
+	}
+	if settings.Type == "graphql" {
+		fields := make(map[string][]FieldData, len(Entities))
+		for _, entity := range Entities {
+			fields[entity] = entityFieldData(entity, entityFields[entity], relations[entity])
+		}
+		data.GraphQLHelpers = graphqlHelpers(Entities, fields, relations)
+	}
+	if projectKind.Commands {
+		data.Commands = resolveCommands(yamlConfig)
+	}
+	if projectKind.Jobs {
+		data.Jobs = resolveJobs(yamlConfig)
+	}
 
-	// Render templates
-	_ = renderTemplateDir("common", projectName, data)
-	_ = renderTemplateDir("rest/clean", projectName, data)
-
-	if DBType != "" {
+	if len(stores) > 0 {
 		jobs = append(jobs,
-			TemplateJob{"db/" + DBType, projectName},
-			TemplateJob{"db/database", filepath.Join(projectName, "internal", "db")},
+			TemplateJob{"db/database", filepath.Join(projectDir, filepath.FromSlash(settings.Layout.DBDir))},
 		)
 
 		// fmt.Fprintf(out, "✓ Added database support for '%s'\n", DBType)
 	}
 
+	jobs = append(jobs, features.templateJobs(projectDir)...)
+	if len(data.Realtime) > 0 {
+		jobs = append(jobs, TemplateJob{realtimeDir, projectDir})
+		if dir := settings.Layout.RealtimeDir; dir != "" {
+			jobs = append(jobs, TemplateJob{dir, projectDir})
+		}
+	}
+
 	for _, job := range jobs {
 		if err := renderTemplateDir(job.TemplateDir, job.DestDir, data); err != nil {
 			fmt.Fprintf(out, "Error rendering template %s → %s: %v\n",
 				job.TemplateDir, job.DestDir, err)
-			return
+			return TemplateData{}, false
+		}
+	}
+
+	if dir := settings.Layout.ProtoDir; dir != "" {
+		if err := writeProtoCode(projectDir, dir, projectName); err != nil {
+			fmt.Fprintf(out, "Error generating code from %s: %v\n", dir, err)
+			return TemplateData{}, false
+		}
+	}
+
+	// ✅ COPY project.yaml if provided, over the one rendered from common
+	if err := copyProjectYAML(specPath, projectDir); err != nil {
+		fmt.Fprintf(out, "warning: could not copy project.yaml: %v\n", err)
+	} else if err := pinSettings(filepath.Join(projectDir, "project.yaml"), pinned); err != nil {
+		fmt.Fprintf(out, "warning: could not record the flags in project.yaml: %v\n", err)
+	}
+
+	if err := writeMigrations(projectDir, stores, Entities, entityStore, entityFields, relations); err != nil {
+		fmt.Fprintf(out, "Error writing migrations: %v\n", err)
+		return TemplateData{}, false
+	}
+
+	if err := writeProfiles(projectDir, data.Profiles); err != nil {
+		fmt.Fprintf(out, "Error writing profiles: %v\n", err)
+		return TemplateData{}, false
+	}
+
+	// Projects that run no server have no container to compose.
+	if projectKind.Healthcheck != nil {
+		if err := writeCompose(projectDir, projectKind.Healthcheck, stores, features.Services()...); err != nil {
+			fmt.Fprintf(out, "Error writing docker-compose.yml: %v\n", err)
+			return TemplateData{}, false
 		}
 	}
 
 	fmt.Fprintf(out, "✓ Created '%s' successfully\n", projectName)
+	return data, true
 }
 
 func IsHidden(path string) (bool, error) {
@@ -669,9 +965,22 @@ func renderTemplateDir(templatePath, destinationPath string, data TemplateData)
 		}
 
 		relPath, _ := filepath.Rel(templatePath, path)
+		relPath, ok := packagePath(relPath, data.Layout.Packages)
+		if !ok {
+			return fs.SkipDir
+		}
 
 		if d.IsDir() {
-			return os.MkdirAll(filepath.Join(destinationPath, relPath), 0755)
+			if !strings.Contains(relPath, modulePlaceholder) {
+				return os.MkdirAll(filepath.Join(destinationPath, relPath), 0755)
+			}
+			for _, module := range data.Modules {
+				dir := strings.Replace(relPath, modulePlaceholder, module.Name, 1)
+				if err := os.MkdirAll(filepath.Join(destinationPath, dir), 0755); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
 
 		content, err := templates.FS.ReadFile(path)
@@ -681,11 +990,39 @@ func renderTemplateDir(templatePath, destinationPath string, data TemplateData)
 
 		fileName := strings.TrimSuffix(relPath, ".tmpl")
 
-		if templatePath == "common" {
-			base := filepath.Base(fileName)
+		switch base := filepath.Base(fileName); templatePath {
+		case "common":
 			if base == "env" || base == "golang-ci.yml" {
 				fileName = "." + fileName
 			}
+		case "monorepo":
+			if base == "dockerignore" {
+				fileName = "." + fileName
+			}
+		}
+
+		if strings.Contains(fileName, commandPlaceholder) {
+			for _, command := range data.Commands {
+				commandData := data
+				commandData.Command = command
+				newFile := strings.Replace(fileName, commandPlaceholder, command.File, 1)
+				if err := writeSingle(commandData, newFile, path, content, destinationPath); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if strings.Contains(fileName, jobPlaceholder) {
+			for _, job := range data.Jobs {
+				jobData := data
+				jobData.Job = job
+				newFile := strings.Replace(fileName, jobPlaceholder, job.Kind, 1)
+				if err := writeSingle(jobData, newFile, path, content, destinationPath); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
 
 		if len(data.Entities) == 0 {
@@ -703,8 +1040,42 @@ func renderTemplateDir(templatePath, destinationPath string, data TemplateData)
 			)
 
 			entityData := data
+			entityData.Module = moduleOf(data.Modules, entity)
+			entityData.Packages = data.Layout.Packages.In(entityData.Module.Name)
+			newFile = strings.Replace(newFile, modulePlaceholder, entityData.Module.Name, 1)
 			entityData.Entity = strings.Title(entity)
 			entityData.LowerEntity = strings.ToLower(entity)
+			entityData.SeedJSON = data.Seeds[entity]
+			entityData.Store = data.EntityStore[entity]
+			fields := data.EntityFields[entity]
+			entityData.Fields = entityFieldData(entity, fields, data.Relations[entity])
+			if rd := data.Relations[entity]; rd != nil {
+				entityData.ForeignKeys = rd.ForeignKeys
+				entityData.Associations = rd.Associations
+				entityData.ManyToMany = rd.ManyToMany()
+			}
+			entityData.Patterns = nil
+			entityData.UniqueFields = nil
+			for _, fd := range entityData.Fields {
+				if fd.Pattern != nil {
+					entityData.Patterns = append(entityData.Patterns, *fd.Pattern)
+				}
+				if fd.Spec.Unique {
+					entityData.UniqueFields = append(entityData.UniqueFields, fd)
+				}
+			}
+			entityData.TestImports = distinctImports(entityData.UniqueFields)
+			entityData.Operations = data.EntityOperations[entity]
+			entityData.Streamed = data.Realtime[entity]
+			entityData.ModelImports = fieldImports(fields, "time")
+			entityData.DTOImports = fieldImports(fields)
+			if data.Layout.ProtoDir != "" {
+				entityData.ProtoFields = protoFields(entityData.Fields)
+				entityData.ProtoImports = protoImports(entity, entityData.ProtoFields, entityData.ManyToMany)
+			}
+			if data.ProjectType == "graphql" {
+				entityData.GraphQLFields = graphqlFields(entityData.Fields)
+			}
 			// capture errors!!
 			if err := writeSingle(entityData, newFile, path, content, destinationPath); err != nil {
 				return err
@@ -715,29 +1086,106 @@ func renderTemplateDir(templatePath, destinationPath string, data TemplateData)
 	})
 }
 
-func writeSingle(data TemplateData, fileName string, tmpltPath string, content []byte, destinationPath string) error {
-	newFile := strings.Replace(
-		fileName,
-		"example",
-		"user",
-		1, // only first replacement
-	)
+// packagePath maps the directories of the templates the layered
+// architectures share to the packages they stand for, leaving other paths
+// as they are. It reports false for the packages the architecture lacks.
+func packagePath(relPath string, packages layout.Packages) (string, bool) {
+	first, rest, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+	dir, shared := packages.Dir(first)
+	switch {
+	case !shared:
+		return relPath, true
+	case dir == "":
+		return "", false
+	}
+	return filepath.FromSlash(path.Join(dir, rest)), true
+}
 
+// userOwnedSuffix marks generated files that hold user code, such as the
+// custom_logic stubs. They are written once and never overwritten.
+const userOwnedSuffix = "_custom.go"
+
+func isUserOwned(path string) bool {
+	return strings.HasSuffix(path, userOwnedSuffix)
+}
+
+// templateFuncs are available to every template.
+var templateFuncs = template.FuncMap{
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+	"pascal": naming.Pascal,
+	"camel":  naming.Camel,
+	"snake":  naming.Snake,
+	"plural": naming.Plural,
+	"table":  tableName,
+	"path":   resourcePath,
+	// protogo is the Go name protoc-gen-go gives a proto name, and add
+	// numbers the fields of messages.
+	"protogo": rpcgen.GoName,
+	"add":     func(a, b int) int { return a + b },
+	// graphqlName names fields and arguments in GraphQL schemas.
+	"graphqlName": graphqlName,
+	// contains lets files that share a package with router snippets skip
+	// imports those already bring in.
+	"contains": strings.Contains,
+	// jobsYAML writes the jobs of worker projects back to project.yaml.
+	"jobsYAML": jobsYAML,
+	// pkgname is the package name of library modules.
+	"pkgname": packageName,
+	// imports lists the layers a shared template uses.
+	"imports": importPackages,
+}
+
+// importPackages returns the import specs, one per line, of the packages of
+// module that code in package in uses. Architectures merge some layers into
+// one package, which is imported once, and never into in itself.
+func importPackages(module string, in layout.Package, pkgs ...layout.Package) string {
+	var specs []string
+	seen := map[string]bool{in.Path: true}
+	for _, pkg := range pkgs {
+		if !seen[pkg.Path] {
+			seen[pkg.Path] = true
+			specs = append(specs, pkg.Import(module))
+		}
+	}
+	return strings.Join(specs, "\n\t")
+}
+
+func writeSingle(data TemplateData, fileName string, tmpltPath string, content []byte, destinationPath string) error {
 	entityData := data
-	entityData.Entity = strings.Title("user")
-	entityData.LowerEntity = strings.ToLower("user")
-	targetPath := filepath.Join(destinationPath, newFile)
+	if entityData.Entity == "" {
+		entityData.Entity = strings.Title("user")
+		entityData.LowerEntity = strings.ToLower("user")
+	}
+	targetPath := filepath.Join(destinationPath, fileName)
 
-	tmpl, err := template.New(filepath.Base(tmpltPath)).Parse(string(content))
+	tmpl, err := template.New(filepath.Base(tmpltPath)).Funcs(templateFuncs).Parse(string(content))
 	if err != nil {
 		return err
 	}
 
-	outFile, err := os.Create(targetPath)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, entityData); err != nil {
 		return err
 	}
-	defer outFile.Close()
 
-	return tmpl.Execute(outFile, entityData)
+	out := buf.Bytes()
+	// Templates render to nothing for entities they do not apply to.
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil
+	}
+	if isUserOwned(targetPath) {
+		if _, err := os.Stat(targetPath); err == nil {
+			return nil
+		}
+	}
+	if strings.HasSuffix(targetPath, ".go") {
+		// Leave code that does not parse as is, so that the compiler points
+		// at the problem in the generated project.
+		if formatted, err := format.Source(out); err == nil {
+			out = formatted
+		}
+	}
+
+	return os.WriteFile(targetPath, out, 0644)
 }
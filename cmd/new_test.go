@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
 )
 
 func TestCreateNewProject_Success(t *testing.T) {
@@ -23,15 +27,16 @@ func TestCreateNewProject_Success(t *testing.T) {
 	assert.NoError(t, err, "Failed to change to temp directory")
 
 	var out bytes.Buffer
-	createNewProject(projectName, "gin", "go", &out)
+	createNewProject(projectName, "gin", "rest", &out)
 
 	// Check if directory was created
 	_, err = os.Stat(fullPath)
 	assert.NoError(t, err, "Expected project directory to be created")
 
-	// Check output
-	expected := fmt.Sprintf("✓ Created '%s' successfully\n", projectName)
-	fmt.Println(out.String())
+	// Check output: the effective settings, then the result
+	expected := fmt.Sprintf("Project:   %s\nLocation:  %s\nType:      rest\nArch:      clean\n"+
+		"Router:    gin\nPort:      8080\nDatabases: none\nFeatures:  none\n"+
+		"✓ Created '%s' successfully\n", projectName, projectName, projectName)
 	assert.Equal(t, expected, out.String(), "Unexpected output")
 }
 
@@ -59,10 +64,1190 @@ func TestCreateNewProject_InvalidPath(t *testing.T) {
 	invalidPath := filepath.Join(tempDir, projectName)
 
 	var out bytes.Buffer
-	createNewProject(projectName, "gin", "go ", &out)
+	createNewProject(projectName, "gin", "rest", &out)
 
 	_, err := os.Stat(invalidPath)
 	assert.Error(t, err, "Expected no directory to be created")
 
 	assert.Contains(t, out.String(), "Error creating directory", "Expected error message")
 }
+
+func TestCreateNewProject_GeneratesRepositoryFakes(t *testing.T) {
+	tempDir := t.TempDir()
+	projectName := "fake-project"
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	var out bytes.Buffer
+	createNewProject(projectName, "gin", "rest", &out)
+
+	for _, file := range []string{
+		"internal/repository/errors.go",
+		"internal/repository/user_repo.go",
+		"internal/repository/memory/user_repo.go",
+		"internal/service/user_service_test.go",
+	} {
+		_, err := os.Stat(filepath.Join(tempDir, projectName, file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+
+	service, err := os.ReadFile(filepath.Join(tempDir, projectName, "internal/service/user_service.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(service), "func NewUserService(repo repository.UserRepository) UserService")
+
+	fake, err := os.ReadFile(filepath.Join(tempDir, projectName, "internal/repository/memory/user_repo.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(fake), `return fmt.Errorf("%w: id %d", repository.ErrConflict, user.ID)`)
+	assert.NotContains(t, string(fake), "r.unique(", "the default fields are not unique")
+}
+
+func TestSeedData_AssignsMissingIDs(t *testing.T) {
+	config := &parser.Config{Entities: []parser.Entity{
+		{Name: "user", Seeds: &parser.Seeds{Rows: []map[string]any{
+			{"name": "Ada"},
+			{"id": 5, "name": "Grace"},
+		}}},
+		{Name: "product"},
+	}}
+
+	seeds, err := seedData(config, t.TempDir())
+	assert.NoError(t, err)
+	assert.NotContains(t, seeds, "product")
+	assert.JSONEq(t, `[{"id": 6, "name": "Ada"}, {"id": 5, "name": "Grace"}]`, seeds["user"])
+}
+
+func TestResolveStores(t *testing.T) {
+	config := &parser.Config{
+		Databases: map[string]parser.Datastore{
+			"primary":   {Database: "postgres"},
+			"analytics": {Database: "sqlite"},
+		},
+		Entities: []parser.Entity{{Name: "user"}, {Name: "event", Store: "analytics"}},
+	}
+
+	stores, entityStore, err := resolveStores(config, "", []string{"user", "event"})
+	assert.NoError(t, err)
+	assert.Len(t, stores, 2)
+	assert.Equal(t, "primary", stores[0].Name)
+	assert.Equal(t, "GONE_DB", stores[0].Prefix)
+	assert.Equal(t, "GONE_ANALYTICS_DB", stores[1].Prefix)
+	assert.Equal(t, "analytics.db", stores[1].Database)
+	assert.Equal(t, map[string]string{"user": "primary", "event": "analytics"}, entityStore)
+
+	config.Entities[1].Store = "warehouse"
+	_, _, err = resolveStores(config, "", []string{"user", "event"})
+	assert.EqualError(t, err, `entity "event" uses store "warehouse", which is not declared under databases`)
+
+	_, _, err = resolveStores(nil, "mongodb", []string{"user"})
+	assert.EqualError(t, err, `store "primary": mongodb is not supported by the generated repositories`)
+}
+
+func TestCreateNewProject_TypedFields(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: shop
+  router: chi
+  db: postgres
+entities:
+  - name: product
+    fields:
+      - {name: title, type: string, unique: true}
+      - {name: price, type: decimal}
+      - {name: notes, type: text, nullable: true}
+      - {name: order, type: int, index: true}
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "chi", "rest", &out)
+	assert.Contains(t, out.String(), "✓ Created 'shop' successfully\n")
+
+	model, err := os.ReadFile(filepath.Join("shop", "internal/model/product_model.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(model), "Title     string ")
+	assert.Contains(t, string(model), "Notes     *string ")
+	assert.NotContains(t, string(model), "gorm.Model")
+
+	_, err = os.Stat(filepath.Join("shop", "internal/dto/product_dto.go"))
+	assert.NoError(t, err)
+
+	migration, err := os.ReadFile(filepath.Join("shop", "migrations/0001_create_products.up.sql"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(migration), `CREATE TABLE "products" (`)
+	assert.Contains(t, string(migration), `"price" numeric(12,2) NOT NULL`)
+	assert.Contains(t, string(migration), `"order" integer NOT NULL`, "reserved words should be quoted")
+	assert.Contains(t, string(migration), `CREATE UNIQUE INDEX idx_products_title ON "products" ("title");`)
+	assert.Contains(t, string(migration), `CREATE INDEX idx_products_order ON "products" ("order");`)
+
+	routes, err := os.ReadFile(filepath.Join("shop", "internal/server/routes.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(routes), `r.Put("/api/v1/products/{id}", productHandler.UpdateProduct)`)
+}
+
+func TestAddRules(t *testing.T) {
+	min, max := 2.0, 20.0
+	fields := fieldData([]parser.Field{
+		{Name: "nick", Type: "string", Validate: &parser.Rules{Required: true, Min: &min, Max: &max}},
+		{Name: "status", Type: "string", Validate: &parser.Rules{OneOf: []string{"active", "inactive"}}},
+		{Name: "code", Type: "string", Validate: &parser.Rules{Regex: `^[A-Z]{3}$`}},
+		{Name: "notes", Type: "text"},
+	})
+	addRules("user", fields)
+
+	assert.Equal(t, "required,min=2,max=20", fields[0].CreateRules)
+	assert.Equal(t, "omitempty,nonzero,min=2,max=20", fields[0].UpdateRules)
+	assert.Equal(t, "omitempty,oneof=active inactive", fields[1].CreateRules)
+	assert.Equal(t, "omitempty,pattern=user_code", fields[2].CreateRules)
+	assert.Equal(t, &PatternData{Key: "user_code", Regex: `"^[A-Z]{3}$"`}, fields[2].Pattern)
+	assert.Empty(t, fields[3].CreateRules)
+	assert.Empty(t, fields[3].UpdateRules)
+}
+
+func TestFieldData_Conflicts(t *testing.T) {
+	fields := fieldData([]parser.Field{
+		{Name: "email", Type: "string", Unique: true},
+		{Name: "nick", Type: "string", Unique: true, Nullable: true},
+		{Name: "born", Type: "time", Unique: true},
+		{Name: "seen", Type: "time", Unique: true, Nullable: true},
+		{Name: "token", Type: "bytes", Unique: true},
+		{Name: "rank", Type: "int", Unique: true},
+	})
+
+	tests := []struct {
+		conflicts string
+		distinct  string
+	}{
+		{"a.Email == b.Email", "fmt.Sprint(id)"},
+		{"a.Nick != nil && b.Nick != nil && *a.Nick == *b.Nick", ""},
+		{"a.Born.Equal(b.Born)", "time.Unix(int64(id), 0)"},
+		{"a.Seen != nil && b.Seen != nil && a.Seen.Equal(*b.Seen)", ""},
+		{"a.Token != nil && b.Token != nil && string(a.Token) == string(b.Token)", ""},
+		{"a.Rank == b.Rank", "int(id)"},
+	}
+	for i, tt := range tests {
+		t.Run(fields[i].JSON, func(t *testing.T) {
+			assert.Equal(t, tt.conflicts, fields[i].Conflicts("a", "b"))
+			assert.Equal(t, tt.distinct, fields[i].Distinct("id"))
+		})
+	}
+	assert.Equal(t, []string{"fmt", "time"}, distinctImports(fields))
+}
+
+func TestCreateNewProject_CustomLogic(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: shop
+  router: gin
+entities:
+  - user
+  - product
+  - order
+custom_logic:
+  user:
+    - { name: activate }
+  product:
+    - { name: search, method: GET, path: /search, query: [q] }
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "gin", "rest", &out)
+	assert.Contains(t, out.String(), "✓ Created 'shop' successfully\n")
+
+	stub, err := os.ReadFile(filepath.Join("shop", "internal/service/product_custom.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(stub), "func (s *productService) SearchProduct(q string) ([]model.Product, error) {")
+	assert.Contains(t, string(stub), "// TODO: implement search.")
+
+	routes, err := os.ReadFile(filepath.Join("shop", "internal/server/routes.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(routes), `r.POST("/api/v1/users/:id/activate", userHandler.ActivateUser)`)
+	assert.Contains(t, string(routes), `r.GET("/api/v1/products/search", productHandler.SearchProduct)`)
+
+	_, err = os.Stat(filepath.Join("shop", "internal/repository/memory/user_custom.go"))
+	assert.NoError(t, err)
+	// Entities without operations get no custom files.
+	_, err = os.Stat(filepath.Join("shop", "internal/service/order_custom.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteSingle_KeepsUserOwnedFiles(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "user_custom.go")
+	assert.NoError(t, os.WriteFile(target, []byte("package service // edited\n"), 0644))
+
+	err := writeSingle(TemplateData{}, "user_custom.go", "user_custom.go.tmpl", []byte("package service\n"), dir)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "package service // edited\n", string(content))
+}
+
+func TestCreateNewProject_RejectsUnknownFieldType(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := "project:\n  name: shop\n  router: gin\nentities:\n  - name: product\n    fields:\n      - {name: price, type: money}\n"
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "gin", "rest", &out)
+	assert.Contains(t, out.String(), `field "price" has unknown type "money"`)
+
+	_, err = os.Stat(filepath.Join("shop", "internal/model/product_model.go"))
+	assert.True(t, os.IsNotExist(err), "no code should be generated for an invalid spec")
+}
+
+func TestResolveRelations(t *testing.T) {
+	config := &parser.Config{Entities: []parser.Entity{
+		{Name: "order", Relations: []parser.Relation{{Type: parser.BelongsTo, Entity: "user", Required: true}}},
+		{Name: "user", Relations: []parser.Relation{
+			{Type: parser.HasMany, Entity: "order"},
+			{Type: parser.ManyToMany, Entity: "role"},
+		}},
+		{Name: "role"},
+	}}
+	entities := []string{"order", "user", "role"}
+
+	relations := resolveRelations(config, entities)
+	assert.Equal(t, []string{"user", "order", "role"}, orderEntities(entities, relations))
+
+	// belongs_to and has_many describe the same key; the stricter wins.
+	assert.Len(t, relations["order"].ForeignKeys, 1)
+	fk := relations["order"].ForeignKeys[0]
+	assert.Equal(t, "UserID", fk.Field.Name)
+	assert.Equal(t, "uint", fk.Field.Type)
+	assert.Equal(t, "GetOrdersByUser", fk.Method)
+	assert.Equal(t, "/api/v1/users/{id}/orders", fk.Path)
+
+	// Both sides of the key know it, as it ended up.
+	assert.Equal(t, fk, relations["order"].Associations[0].ForeignKey)
+	assert.Equal(t, fk, relations["user"].Associations[0].ForeignKey)
+
+	m2m := relations["user"].ManyToMany()
+	assert.Len(t, m2m, 1)
+	assert.Equal(t, "role_users", m2m[0].JoinTable)
+	assert.Equal(t, "/api/v1/users/{id}/roles", m2m[0].Path)
+
+	err := checkRelationStores(entities, relations, map[string]string{"order": "primary", "user": "archive", "role": "archive"})
+	assert.EqualError(t, err, `entity "order" (store "primary") cannot relate to "user" (store "archive"); related entities must share a store`)
+}
+
+func TestGraphQLFields(t *testing.T) {
+	fields := graphqlFields(fieldData([]parser.Field{
+		{Name: "title", Type: "string"},
+		{Name: "released_at", Type: "time", Nullable: true},
+		{Name: "stock", Type: "int", Default: "0"},
+		{Name: "views", Type: "int64"},
+	}))
+
+	tests := []struct {
+		gql, typ, create            string
+		resolve, toCreate, toUpdate string
+	}{
+		{"title", "String!", "String!", "%s", "%s", "%s"},
+		{"releasedAt", "Time", "Time", "toTimePtr(%s)", "fromTimePtr(%s)", "fromTimePtr(%s)"},
+		{"stock", "Int!", "Int", "int32(%s)", "int(valueOf(%s))", "convertPtr[int](%s)"},
+		{"views", "Int64!", "Int64!", "Int64(%s)", "int64(%s)", "convertPtr[int64](%s)"},
+	}
+	for i, tt := range tests {
+		gf := fields[i]
+		assert.Equal(t, tt.gql, gf.GQL)
+		assert.Equal(t, tt.typ, gf.Type, gf.GQL)
+		assert.Equal(t, tt.create, gf.CreateType, gf.GQL)
+		assert.Equal(t, []string{tt.resolve, tt.toCreate, tt.toUpdate}, []string{gf.Resolve, gf.ToCreate, gf.ToUpdate}, gf.GQL)
+	}
+}
+
+func TestResolveFeatures(t *testing.T) {
+	config := &parser.Config{Features: parser.Features{Cache: parser.FeatureItem{Name: "redis"}}}
+
+	features, err := resolveFeatures(config, []string{"queue=rabbitmq", "auth"})
+	assert.NoError(t, err)
+	assert.Equal(t, "redis", features.Cache.Provider)
+	assert.Equal(t, "GONE_BROKER_URL", features.Queue.EnvKey)
+	assert.Equal(t, "jwt", features.Auth.Provider)
+	assert.Nil(t, features.Auth.Service)
+	assert.Len(t, features.Services(), 2)
+
+	_, err = resolveFeatures(nil, []string{"mail"})
+	assert.EqualError(t, err, `unknown feature "mail" (expected one of auth, cache, queue)`)
+
+	_, err = resolveFeatures(nil, []string{"queue=kafka"})
+	assert.EqualError(t, err, `unknown queue provider "kafka" (expected one of nats, rabbitmq)`)
+}
+
+func TestCreateNewProject_Features(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: shop
+  router: chi
+features:
+  cache: redis
+entities:
+  - user
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	featureFlags = []string{"auth"}
+	defer func() { YAMLPath, DBType, Entities, featureFlags = "", "", nil, nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "chi", "rest", &out)
+	assert.Contains(t, out.String(), "✓ Created 'shop' successfully\n")
+
+	for _, file := range []string{"internal/cache/cache.go", "internal/cache/memory.go", "internal/auth/jwt.go"} {
+		_, err = os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, file)
+	}
+	_, err = os.Stat(filepath.Join("shop", "internal/queue"))
+	assert.True(t, os.IsNotExist(err))
+
+	env, err := os.ReadFile(filepath.Join("shop", ".env"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(env), "GONE_CACHE_ADDR=localhost:6379")
+	assert.Contains(t, string(env), "GONE_AUTH_SECRET=")
+
+	compose, err := os.ReadFile(filepath.Join("shop", "docker-compose.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(compose), "redis")
+}
+
+func TestResolveProject(t *testing.T) {
+	config := &parser.Config{Project: parser.Project{
+		Name: "shop", Location: "services", Router: "chi", Port: 9000, Database: "sqlite",
+	}}
+
+	settings, err := resolveProject(config, ProjectSettings{Router: "echo"})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("services", "shop"), settings.Dir())
+	assert.Equal(t, "rest", settings.Type)
+	assert.Equal(t, "clean", settings.Arch)
+	assert.Equal(t, []string{"shared", "rest/shared", "layers/shared", "layers/clean", "rest/layered", "rest/clean"}, settings.Layout.TemplateDirs)
+	assert.Equal(t, "echo", settings.Router, "flags override the spec")
+	assert.Equal(t, "9000", settings.Port)
+	assert.Equal(t, "sqlite", settings.DB)
+
+	settings, err = resolveProject(nil, ProjectSettings{Name: "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "gin", settings.Router)
+	assert.Equal(t, "x", settings.Dir())
+
+	settings, err = resolveProject(nil, ProjectSettings{Name: "x", Type: "grpc"})
+	assert.NoError(t, err)
+	assert.Empty(t, settings.Router, "only HTTP projects get the default router")
+
+	settings, err = resolveProject(nil, ProjectSettings{Name: "x", Type: "graphql"})
+	assert.NoError(t, err)
+	assert.Equal(t, "gin", settings.Router)
+	assert.Equal(t, []string{"shared", "layers/shared", "layers/clean", "graphql/clean"}, settings.Layout.TemplateDirs)
+
+	settings, err = resolveProject(nil, ProjectSettings{Name: "x", Type: "cli"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cobra", settings.Arch)
+	assert.Empty(t, settings.Router)
+	assert.Empty(t, settings.Port, "only servers get the default port")
+
+	settings, err = resolveProject(nil, ProjectSettings{Name: "x", Type: "worker", DB: "postgres"})
+	assert.NoError(t, err)
+	assert.Equal(t, "queue", settings.Arch)
+	assert.Empty(t, settings.Router)
+	assert.Equal(t, "8080", settings.Port, "workers serve their health and admin API")
+
+	modular := &parser.Config{Project: parser.Project{Name: "shop"}, Modules: []parser.Module{{Name: "sales", Entities: []string{"user"}}}}
+	settings, err = resolveProject(modular, ProjectSettings{})
+	assert.NoError(t, err)
+	assert.Equal(t, "modular", settings.Arch, "specs with modules default to the modular arch")
+	_, err = resolveProject(modular, ProjectSettings{Arch: "clean"})
+	assert.EqualError(t, err, `modules need arch "modular", not "clean"`)
+
+	tests := []struct {
+		flags   ProjectSettings
+		wantErr string
+	}{
+		{ProjectSettings{}, "project name is required"},
+		{ProjectSettings{Name: "x", Type: "soap"}, `unknown project type "soap" (expected one of cli, graphql, grpc, library, rest, worker)`},
+		{ProjectSettings{Name: "x", Arch: "onion"}, `unknown arch "onion" for rest projects (expected one of clean, hexagonal, minimal, modular)`},
+		{ProjectSettings{Name: "x", Router: "gim"}, `unknown router "gim" (expected one of chi, echo, fiber, gin, mux)`},
+		{ProjectSettings{Name: "x", Type: "grpc", Router: "gin"}, `grpc projects take no router, but "gin" is set`},
+		{ProjectSettings{Name: "x", Port: "80a"}, `invalid port "80a" (expected 1-65535)`},
+		{ProjectSettings{Name: "x", Type: "cli", Port: "9000"}, `cli projects take no port, but 9000 is set`},
+		{ProjectSettings{Name: "x", Type: "cli", DB: "postgres"}, `cli projects take no database, but "postgres" is set`},
+		{ProjectSettings{Name: "x", Type: "worker", Router: "chi"}, `worker projects take no router, but "chi" is set`},
+	}
+	for _, tt := range tests {
+		_, err := resolveProject(nil, tt.flags)
+		assert.EqualError(t, err, tt.wantErr)
+	}
+}
+
+func TestCreateNewProject_Location(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := "project:\n  name: shop\n  location: apps/backend\n  arch: clean\nentities:\n  - user\n"
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "", "", &out)
+	assert.Contains(t, out.String(), "Location:  apps/backend/shop\n")
+	assert.Contains(t, out.String(), "Router:    gin\n")
+
+	_, err = os.Stat(filepath.Join("apps", "backend", "shop", "cmd", "main.go"))
+	assert.NoError(t, err)
+	copied, err := os.ReadFile(filepath.Join("apps", "backend", "shop", "project.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, spec, string(copied))
+}
+
+func TestResolveProfiles(t *testing.T) {
+	stores := []StoreData{{Name: "primary", Dialect: "postgres", Prefix: "GONE_DB", HostPort: "5431", Database: "gone"}}
+	settings := resolveSettings("8080", stores, FeaturesData{})
+	config := &parser.Config{Profiles: []parser.Profile{
+		{Name: "prod", Env: map[string]string{"GONE_DB_HOST": "db.internal", "LOG_LEVEL": "warn # quiet"}},
+	}}
+
+	profiles := resolveProfiles(config, settings)
+	assert.Len(t, profiles, 1)
+
+	dir := t.TempDir()
+	assert.NoError(t, writeProfiles(dir, profiles))
+	content, err := os.ReadFile(filepath.Join(dir, ".env.prod"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "APP_ENV=prod\nPORT=8080\nGONE_DB_HOST=db.internal\nGONE_DB_PORT=\n"+
+		"GONE_DB_DATABASE=gone\nGONE_DB_USERNAME=\nGONE_DB_PASSWORD=\nGONE_DB_SCHEMA=public\nLOG_LEVEL='warn # quiet'\n")
+}
+
+func TestCreateNewProject_TOMLSpec(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := "entities = [\"user\"]\n\n[project]\nname = \"shop\"\nrouter = \"chi\"\n"
+	assert.NoError(t, os.WriteFile("project.toml", []byte(spec), 0644))
+	YAMLPath = "project.toml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "", "", &out)
+	assert.Contains(t, out.String(), "✓ Created 'shop' successfully\n")
+
+	copied, err := os.ReadFile(filepath.Join("shop", "project.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "entities:\n  - user\nproject:\n  name: shop\n  router: chi\n", string(copied))
+}
+
+func TestCreateNewProject_Hexagonal(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	projectArch, DBType = "hexagonal", "sqlite"
+	defer func() { projectArch, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	createNewProject("shop", "chi", "rest", &out)
+	assert.Contains(t, out.String(), "Arch:      hexagonal\n")
+	assert.Contains(t, out.String(), "✓ Created 'shop' successfully\n")
+
+	for _, file := range []string{
+		"internal/core/domain/user.go",
+		"internal/core/ports/user.go",
+		"internal/core/services/user_service.go",
+		"internal/adapters/http/user_handler.go",
+		"internal/adapters/persistence/user_repo.go",
+		"internal/adapters/persistence/memory/user_repo.go",
+		"internal/adapters/persistence/db/database.go",
+		"internal/config/config.go",
+	} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	for _, dir := range []string{"internal/model", "internal/repository", "internal/db"} {
+		_, err := os.Stat(filepath.Join("shop", dir))
+		assert.True(t, os.IsNotExist(err), "%s belongs to the clean layout", dir)
+	}
+
+	domain, err := os.ReadFile(filepath.Join("shop", "internal/core/domain/user.go"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(domain), "gorm", "the core does not depend on persistence")
+
+	database, err := os.ReadFile(filepath.Join("shop", "internal/adapters/persistence/db/database.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(database), `model "shop/internal/adapters/persistence"`)
+	assert.Contains(t, string(database), `"?_pragma=foreign_keys(1)"`, "sqlite should enforce foreign keys")
+
+	violation, err := os.ReadFile(filepath.Join("shop", "internal/adapters/persistence/violation.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(violation), `return fmt.Errorf("%w: %v", domain.ErrNotFound, err)`)
+}
+
+func TestCreateNewProject_Minimal(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	projectArch, DBType, Entities = "minimal", "sqlite", []string{"user", "order"}
+	defer func() { projectArch, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("shop", "echo", "rest", &out))
+	assert.Contains(t, out.String(), "Arch:      minimal\n")
+
+	for _, file := range []string{"main.go", "user.go", "order.go", "seeds/user.json", "internal/db/database.go", "internal/config/config.go"} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	for _, dir := range []string{"cmd", "internal/handler", "internal/model", "internal/service"} {
+		_, err := os.Stat(filepath.Join("shop", dir))
+		assert.True(t, os.IsNotExist(err), "%s belongs to the clean layout", dir)
+	}
+
+	main, err := os.ReadFile(filepath.Join("shop", "main.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(main), "&User{},\n\t\t&Order{},")
+
+	database, err := os.ReadFile(filepath.Join("shop", "internal/db/database.go"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(database), "AutoMigrate", "main migrates the models of minimal projects")
+
+	makefile, err := os.ReadFile(filepath.Join("shop", "Makefile"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(makefile), "MAIN_PKG := .\n")
+	assert.Contains(t, string(makefile), "$(GO) run . seed")
+}
+
+func TestCreateNewProject_Modular(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := "project:\n  name: shop\n  db: sqlite\nmodules:\n  - { name: sales, entities: [user, order] }\n  - { name: catalog, entities: [product] }\nentities:\n  - user\n  - order\n  - product\n"
+	assert.NoError(t, os.WriteFile("spec.yaml", []byte(spec), 0644))
+
+	YAMLPath = "spec.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("", "chi", "", &out), out.String())
+	assert.Contains(t, out.String(), "Arch:      modular\n")
+
+	for _, file := range []string{
+		"internal/modules/modules.go",
+		"internal/modules/arch_test.go",
+		"internal/modules/sales/module.go",
+		"internal/modules/sales/handler/order_handler.go",
+		"internal/modules/sales/handler/user_dto.go",
+		"internal/modules/sales/service/user_service.go",
+		"internal/modules/sales/repository/order_repo.go",
+		"internal/modules/sales/model/user_model.go",
+		"internal/modules/catalog/model/product_model.go",
+		"internal/seed/seed.go",
+	} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	for _, path := range []string{"internal/modules/modulename", "internal/modules/catalog/model/user_model.go", "internal/handler", "internal/model"} {
+		_, err := os.Stat(filepath.Join("shop", path))
+		assert.True(t, os.IsNotExist(err), "%s should not be generated", path)
+	}
+
+	routes, err := os.ReadFile(filepath.Join("shop", "internal/server/routes.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(routes), "sales.New(s.dbs),\n\t\tcatalog.New(s.dbs),")
+	assert.Contains(t, string(routes), "r.Group(func(r chi.Router) { module.RegisterRoutes(r) })")
+
+	module, err := os.ReadFile(filepath.Join("shop", "internal/modules/catalog/module.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(module), `r.Get("/api/v1/products", m.productHandler.GetProducts)`)
+	assert.NotContains(t, string(module), "User")
+}
+
+func TestCreateNewProject_PinsFlags(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := "# the shop\nproject:\n  name: shop\n  router: gin # for now\nentities:\n  - user\n"
+	assert.NoError(t, os.WriteFile("spec.yaml", []byte(spec), 0644))
+
+	YAMLPath, projectArch = "spec.yaml", "minimal"
+	defer func() { YAMLPath, projectArch, DBType, Entities = "", "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("", "chi", "", &out), out.String())
+
+	got, err := os.ReadFile(filepath.Join("shop", "project.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# the shop\nproject:\n  name: shop\n  router: chi # for now\n  arch: minimal\nentities:\n  - user\n", string(got))
+}
+
+func TestCreateNewProject_GRPC(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: shop
+  type: grpc
+  db: sqlite
+entities:
+  - name: user
+    fields:
+      - { name: email, type: string, validate: { required: true } }
+      - { name: born_at, type: time, nullable: true }
+    relations:
+      - { type: many_to_many, entity: role }
+  - role
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("", "", "", &out), out.String())
+	assert.Contains(t, out.String(), "Type:      grpc\n")
+	assert.NotContains(t, out.String(), "Router:")
+
+	for _, file := range []string{
+		"proto/api/v1/user.proto",
+		"proto/api/v1/user_service.proto",
+		"gen/api/v1/user.pb.go",
+		"gen/api/v1/user_service.pb.go",
+		"gen/api/v1/user_service_grpc.pb.go",
+		"gen/api/v1/role_service_grpc.pb.go",
+		"internal/rpc/user_server.go",
+		"internal/rpc/convert.go",
+		"internal/server/server.go",
+		"internal/service/user_service.go",
+		"internal/repository/user_repo.go",
+		"cmd/healthcheck.go",
+	} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	_, err = os.Stat(filepath.Join("shop", "internal/handler"))
+	assert.True(t, os.IsNotExist(err), "grpc projects have no HTTP handlers")
+
+	service, err := os.ReadFile(filepath.Join("shop", "proto/api/v1/user_service.proto"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(service), `import "api/v1/role.proto";`)
+	assert.Contains(t, string(service), "rpc GetUserRoles(GetUserRolesRequest) returns (GetUserRolesResponse);")
+	assert.Contains(t, string(service), "optional string email = 2;")
+
+	server, err := os.ReadFile(filepath.Join("shop", "internal/server/server.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(server), "apiv1.RegisterUserServiceServer(s.Server, rpc.NewUserServer(service.NewUserService(")
+	assert.Contains(t, string(server), "reflection.Register(s.Server)")
+
+	convert, err := os.ReadFile(filepath.Join("shop", "internal/rpc/convert.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(convert), "func fromTimestampPtr(")
+	assert.NotContains(t, string(convert), "func fromJSON(", "only the helpers the fields need")
+
+	compose, err := os.ReadFile(filepath.Join("shop", "docker-compose.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(compose), "test:\n        - CMD\n        - /app\n        - healthcheck\n")
+}
+
+func TestCreateNewProject_GraphQL(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: shop
+  type: graphql
+  router: chi
+  db: sqlite
+entities:
+  - name: user
+    fields:
+      - { name: email, type: string, validate: { required: true } }
+      - { name: visits, type: int64, default: "0" }
+    relations:
+      - { type: has_many, entity: order }
+  - name: order
+    fields:
+      - { name: placed_at, type: time, nullable: true }
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("", "", "", &out), out.String())
+	assert.Contains(t, out.String(), "Type:      graphql\n")
+
+	for _, file := range []string{
+		"internal/graph/schema/schema.graphql",
+		"internal/graph/schema/user.graphql",
+		"internal/graph/user_resolver.go",
+		"internal/graph/user_resolver_test.go",
+		"internal/graph/handler.go",
+		"internal/server/routes.go",
+		"internal/service/order_service.go",
+	} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	_, err = os.Stat(filepath.Join("shop", "internal/handler"))
+	assert.True(t, os.IsNotExist(err), "graphql projects have no REST handlers")
+
+	schema, err := os.ReadFile(filepath.Join("shop", "internal/graph/schema/user.graphql"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(schema), "  visits: Int64!\n  orders: [Order!]!\n}")
+	assert.Contains(t, string(schema), "input CreateUserInput {\n  email: String!\n  visits: Int64\n}")
+
+	root, err := os.ReadFile(filepath.Join("shop", "internal/graph/schema/schema.graphql"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(root), "scalar Int64")
+	assert.NotContains(t, string(root), "scalar JSON", "only the scalars the fields need")
+	assert.Contains(t, string(root), "  updateOrder(id: ID!, input: UpdateOrderInput!): Order!\n")
+
+	resolver, err := os.ReadFile(filepath.Join("shop", "internal/graph/user_resolver.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(resolver), "b.root.OrderService.GetOrdersByUserIDs(unique(ids))", "has_many relations are loaded in batches")
+
+	routes, err := os.ReadFile(filepath.Join("shop", "internal/server/routes.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(routes), `r.Handle("/graphql", graph.Handler(graph.MustSchema(resolver)))`)
+
+	repo, err := os.ReadFile(filepath.Join("shop", "internal/repository/order_repo.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(repo), `Where("user_id IN ?", userIDs)`)
+}
+
+func TestResolveCommands(t *testing.T) {
+	commands := resolveCommands(nil)
+	assert.Equal(t, []string{"hello"}, []string{commands[0].Name}, "CLIs without commands get the default one")
+
+	config := &parser.Config{Commands: []parser.Command{{
+		Name: "list-users",
+		Args: []string{"org_id"},
+		Flags: []parser.Flag{
+			{Name: "limit", Type: "int", Default: "020"},
+			{Name: "tags", Type: "strings", Default: "a,b"},
+			{Name: "timeout", Type: "duration", Default: "90s"},
+			{Name: "dry-run", Type: "bool"},
+			{Name: "format"},
+		},
+	}}}
+	commands = resolveCommands(config)
+	assert.Len(t, commands, 1)
+	command := commands[0]
+	assert.Equal(t, "list-users <org_id>", command.Use)
+	assert.Equal(t, "list_users", command.File)
+	assert.Equal(t, "ListUsers", command.Func)
+	assert.Equal(t, "listUsers", command.Var)
+	assert.Equal(t, []ArgData{{Name: "org_id", Field: "OrgID"}}, command.Args)
+	assert.True(t, command.UsesTime())
+
+	tests := []struct {
+		field, goType, method, def, printed string
+	}{
+		{"Limit", "int", "IntVarP", "20", "20"},
+		{"Tags", "[]string", "StringSliceVarP", `[]string{"a", "b"}`, "[a b]"},
+		{"Timeout", "time.Duration", "DurationVarP", "90 * time.Second", "1m30s"},
+		{"DryRun", "bool", "BoolVarP", "false", "false"},
+		{"Format", "string", "StringVarP", `""`, ""},
+	}
+	for i, tt := range tests {
+		flag := command.Flags[i]
+		assert.Equal(t, tt.field, flag.Field)
+		assert.Equal(t, tt.goType, flag.GoType, tt.field)
+		assert.Equal(t, tt.method, flag.Method, tt.field)
+		assert.Equal(t, tt.def, flag.Default, tt.field)
+		assert.Equal(t, tt.printed, flag.Printed, tt.field)
+	}
+}
+
+func TestCreateNewProject_CLI(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: ops
+  type: cli
+commands:
+  - name: list-users
+    description: List the users of an org
+    args: [org]
+    flags:
+      - { name: limit, type: int, short: l, default: "20" }
+  - ping
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("", "", "", &out), out.String())
+	assert.Contains(t, out.String(), "Type:      cli\n")
+	assert.NotContains(t, out.String(), "Port:")
+
+	for _, file := range []string{
+		"main.go",
+		"cmd/root.go",
+		"cmd/version.go",
+		"cmd/config.go",
+		"cmd/list_users.go",
+		"cmd/list_users_custom.go",
+		"cmd/ping.go",
+		"cmd/testdata/list_users.golden",
+		"cmd/testdata/version.golden",
+		"internal/version/version.go",
+		"Makefile",
+		"README.md",
+		".golang-ci.yml",
+	} {
+		_, err := os.Stat(filepath.Join("ops", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	_, err = os.Stat(filepath.Join("ops", "docker-compose.yml"))
+	assert.True(t, os.IsNotExist(err), "CLIs run no container")
+
+	command, err := os.ReadFile(filepath.Join("ops", "cmd/list_users.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(command), `Use:   "list-users <org>",`)
+	assert.Contains(t, string(command), "Args:  cobra.ExactArgs(1),")
+	assert.Contains(t, string(command), `flags.IntVarP(&opts.Limit, "limit", "l", 20, "")`)
+
+	root, err := os.ReadFile(filepath.Join("ops", "cmd/root.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(root), "newListUsersCmd(),\n\t\tnewPingCmd(),")
+
+	golden, err := os.ReadFile(filepath.Join("ops", "cmd/testdata/list_users.golden"))
+	assert.NoError(t, err)
+	assert.Equal(t, "list-users\n  org=org\n  limit=20\n", string(golden))
+
+	makefile, err := os.ReadFile(filepath.Join("ops", "Makefile"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(makefile), "-X $(VERSION_PKG).Version=$(VERSION)")
+	assert.NotContains(t, string(makefile), "seed:")
+
+	_, err = os.Stat(filepath.Join("ops", "project.yaml"))
+	assert.NoError(t, err)
+}
+
+func TestCreateNewProject_CLIRejectsEntities(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	Entities = []string{"user"}
+	defer func() { Entities = nil }()
+
+	var out bytes.Buffer
+	assert.False(t, createNewProject("ops", "", "cli", &out))
+	assert.Contains(t, out.String(), "Error: cli projects take no entities")
+}
+
+func TestResolveJobs(t *testing.T) {
+	jobs := resolveJobs(nil)
+	assert.Equal(t, []string{"send_email"}, []string{jobs[0].Kind}, "workers without jobs get the default one")
+	assert.True(t, jobs[0].HasRequired())
+
+	config := &parser.Config{Jobs: []parser.Job{
+		{
+			Name: "resize_image",
+			Payload: []parser.Field{
+				{Name: "url", Type: "string", Validate: &parser.Rules{Regex: "^https://"}},
+				{Name: "meta", Type: "json"},
+			},
+			MaxAttempts: 3,
+			Timeout:     "90s",
+			Concurrency: 2,
+		},
+		{Name: "ping"},
+	}}
+	jobs = resolveJobs(config)
+	assert.Len(t, jobs, 2)
+
+	job := jobs[0]
+	assert.Equal(t, "ResizeImage", job.Type)
+	assert.Equal(t, "resizeImage", job.Var)
+	assert.Equal(t, 3, job.MaxAttempts)
+	assert.Equal(t, "90 * time.Second", job.TimeoutExpr)
+	assert.Equal(t, 2, job.Concurrency)
+	assert.Equal(t, []string{"context", "encoding/json", "time"}, job.Imports)
+	assert.Equal(t, []PatternData{{Key: "resize_image_url", Regex: `"^https://"`}}, job.Patterns)
+	assert.False(t, job.HasRequired())
+
+	assert.Equal(t, defaultMaxAttempts, jobs[1].MaxAttempts, "jobs without limits get the defaults")
+	assert.Equal(t, "1 * time.Minute", jobs[1].TimeoutExpr)
+
+	out, err := jobsYAML(jobs[1:])
+	assert.NoError(t, err)
+	assert.Equal(t, "jobs:\n  - ping", out)
+}
+
+func TestCreateNewProject_Worker(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	spec := `project:
+  name: jobsvc
+  type: worker
+  db: postgres
+jobs:
+  - name: send_invoice
+    payload:
+      - { name: invoice_id, type: int64, validate: { required: true } }
+    max_attempts: 8
+    concurrency: 4
+  - ping
+`
+	assert.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("", "", "", &out), out.String())
+	assert.Contains(t, out.String(), "Type:      worker\n")
+
+	for _, file := range []string{
+		"cmd/main.go",
+		"internal/queue/queue.go",
+		"internal/queue/memory.go",
+		"internal/queue/db.go",
+		"internal/worker/worker.go",
+		"internal/jobs/jobs.go",
+		"internal/jobs/send_invoice.go",
+		"internal/jobs/send_invoice_custom.go",
+		"internal/jobs/ping.go",
+		"internal/server/server.go",
+		"internal/db/database.go",
+		"docker-compose.yml",
+		"Dockerfile",
+	} {
+		_, err := os.Stat(filepath.Join("jobsvc", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+	_, err = os.Stat(filepath.Join("jobsvc", "internal/model"))
+	assert.True(t, os.IsNotExist(err), "workers have no entities")
+
+	job, err := os.ReadFile(filepath.Join("jobsvc", "internal/jobs/send_invoice.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(job), "InvoiceID int64 `json:\"invoice_id\" validate:\"required\"`")
+	assert.Contains(t, string(job), "MaxAttempts: 8,")
+	assert.Contains(t, string(job), "Concurrency: 4,")
+
+	jobs, err := os.ReadFile(filepath.Join("jobsvc", "internal/jobs/jobs.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(jobs), "w.Register(SendInvoiceKind, worker.Typed(handleSendInvoice), sendInvoiceOptions)")
+
+	main, err := os.ReadFile(filepath.Join("jobsvc", "cmd/main.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(main), "queue.NewDB(store.GetDB())")
+	assert.Contains(t, string(main), "w.Shutdown(ctx)")
+
+	env, err := os.ReadFile(filepath.Join("jobsvc", ".env"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(env), "WORKER_DRAIN_TIMEOUT=30s")
+}
+
+func TestGoDirective(t *testing.T) {
+	tests := map[string]string{
+		"module shop\n\ngo 1.22\n":                   "1.22",
+		"module shop\n\ngo 1.25.0\n\nrequire x v1\n": "1.25",
+		"go 1.23.0\n\nuse ./pkg\n":                   "1.23",
+		"module shop\n":                              "",
+	}
+	for content, want := range tests {
+		assert.Equal(t, want, goDirective(content), content)
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	clean := layout.TypeRegistory["rest"].Archs["clean"].Packages
+	hexagonal := layout.TypeRegistory["rest"].Archs["hexagonal"].Packages
+	modular := layout.TypeRegistory["rest"].Archs["modular"].Packages.In("catalog")
+	tests := []struct {
+		rel      string
+		packages layout.Packages
+		want     string
+		ok       bool
+	}{
+		{"handler/example_handler.go.tmpl", clean, "internal/handler/example_handler.go.tmpl", true},
+		{"handler/example_handler.go.tmpl", hexagonal, "internal/adapters/http/example_handler.go.tmpl", true},
+		{"memory/example_repo.go.tmpl", modular, "internal/modules/catalog/repository/memory/example_repo.go.tmpl", true},
+		{"errors/errors.go.tmpl", modular, "internal/modules/catalog/repository/errors.go.tmpl", true},
+		{"internal/config/config.go.tmpl", hexagonal, "internal/config/config.go.tmpl", true},
+		{"server", layout.Packages{}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := packagePath(filepath.FromSlash(tt.rel), tt.packages)
+		assert.Equal(t, tt.ok, ok, tt.rel)
+		assert.Equal(t, filepath.FromSlash(tt.want), got, tt.rel)
+	}
+}
+
+func TestCreateNewProject_DockerfileGoVersion(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+	for _, kind := range []string{"rest", "grpc", "graphql", "worker"} {
+		t.Run(kind, func(t *testing.T) {
+			spec := fmt.Sprintf("project:\n  name: %[1]s\n  type: %[1]s\n", kind)
+			require.NoError(t, os.WriteFile(kind+".yaml", []byte(spec), 0644))
+			YAMLPath, DBType, Entities = kind+".yaml", "", nil
+
+			var out bytes.Buffer
+			require.True(t, createNewProject("", "", "", &out), out.String())
+
+			gomod, err := os.ReadFile(filepath.Join(kind, "go.mod"))
+			require.NoError(t, err)
+			dockerfile, err := os.ReadFile(filepath.Join(kind, "Dockerfile"))
+			require.NoError(t, err)
+			assert.Contains(t, string(dockerfile), "FROM golang:"+goDirective(string(gomod))+"-alpine AS build")
+
+			// go mod tidy raises the go directive, so tidying retags the image.
+			makefile, err := os.ReadFile(filepath.Join(kind, "Makefile"))
+			require.NoError(t, err)
+			assert.Equal(t, 2, strings.Count(string(makefile), "@$(SYNC_DOCKERFILE)"))
+		})
+	}
+}
+
+func TestCreateNewProject_WorkerWithoutDB(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+
+	var out bytes.Buffer
+	assert.True(t, createNewProject("jobsvc", "", "worker", &out), out.String())
+
+	_, err = os.Stat(filepath.Join("jobsvc", "internal/queue/db.go"))
+	assert.True(t, os.IsNotExist(err), "the DB queue needs a database")
+
+	main, err := os.ReadFile(filepath.Join("jobsvc", "cmd/main.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(main), "q := queue.NewMemory()")
+
+	spec, err := os.ReadFile(filepath.Join("jobsvc", "project.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(spec), "jobs:\n  - name: send_email\n")
+}
+
+func TestCreateNewProject_InvalidEntityFlags(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	assert.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	err = os.Chdir(tempDir)
+	assert.NoError(t, err, "Failed to change to temp directory")
+	defer func() { Entities = nil }()
+
+	for entities, want := range map[string]string{
+		"foo-bar":   `entity name "foo-bar" is not a valid Go identifier`,
+		"type":      `entity name "type" is not a valid Go identifier`,
+		"user,User": `entity "User" is declared twice`,
+		"Err":       `entity name "Err" is reserved by the generated code`,
+	} {
+		Entities = strings.Split(entities, ",")
+		var out bytes.Buffer
+		assert.False(t, createNewProject("shop", "chi", "rest", &out), entities)
+		assert.Contains(t, out.String(), "Error in entities: "+want+"\n")
+
+		_, err := os.Stat("shop")
+		assert.True(t, os.IsNotExist(err), "%s: nothing should be generated", entities)
+	}
+}
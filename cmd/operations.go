@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// OperationData is a custom_logic operation as the templates need it.
+type OperationData struct {
+	Name       string // as declared, e.g. activate
+	Method     string // service, repository and handler method, e.g. ActivateUser
+	HTTPMethod string
+	Path       string // full route, e.g. /api/v1/users/{id}/activate
+	// Member operations act on one record, identified by {id}, and return
+	// it; the others return a list.
+	Member bool
+	Entity string // Go type of the entity
+	Params []ParamData
+}
+
+// ParamData is a path or query parameter of an operation.
+type ParamData struct {
+	Name  string // as it appears in the URL
+	Var   string // Go variable holding it
+	Type  string
+	Query bool
+}
+
+// handlerVars are names the generated handlers already use.
+var handlerVars = map[string]bool{"c": true, "r": true, "w": true, "h": true, "err": true, "result": true, "http": true}
+
+// resolveOperations prepares the custom_logic operations of every entity.
+// The config must have been checked with parser.Config.CheckCustomLogic.
+func resolveOperations(yamlConfig *parser.Config, entities []string) map[string][]OperationData {
+	out := make(map[string][]OperationData, len(entities))
+	if yamlConfig == nil {
+		return out
+	}
+
+	for declared, ops := range yamlConfig.CustomLogic {
+		entity := canonicalEntity(entities, declared)
+		for _, op := range ops {
+			od := OperationData{
+				Name:       op.Name,
+				Method:     naming.Pascal(op.Name) + entityType(entity),
+				HTTPMethod: op.HTTPMethod(),
+				Path:       "/api/v1/" + resourcePath(entity) + strings.TrimSuffix(op.RelativePath(), "/"),
+				Entity:     entityType(entity),
+			}
+
+			for _, name := range op.PathParams() {
+				param := ParamData{Name: name, Var: paramVar(name), Type: "string"}
+				if name == "id" {
+					param.Type = "uint"
+					od.Member = true
+				}
+				od.Params = append(od.Params, param)
+			}
+			for _, name := range op.Query {
+				od.Params = append(od.Params, ParamData{Name: name, Var: paramVar(name), Type: "string", Query: true})
+			}
+
+			out[entity] = append(out[entity], od)
+		}
+	}
+	return out
+}
+
+func paramVar(name string) string {
+	v := naming.Camel(name)
+	if handlerVars[v] {
+		v += "Param"
+	}
+	return v
+}
+
+// Returns is the result type of the operation's methods, with the entity
+// type taken from package pkg, e.g. "*model.User", or from the current
+// package when pkg is empty.
+func (od OperationData) Returns(pkg string) string {
+	entity := od.Entity
+	if pkg != "" {
+		entity = pkg + "." + entity
+	}
+	if od.Member {
+		return "*" + entity
+	}
+	return "[]" + entity
+}
+
+// Args is the parameter list of the operation's methods, e.g.
+// "id uint, q string".
+func (od OperationData) Args() string {
+	args := make([]string, len(od.Params))
+	for i, p := range od.Params {
+		args[i] = p.Var + " " + p.Type
+	}
+	return strings.Join(args, ", ")
+}
+
+// CallArgs passes the parameters on, e.g. "id, q".
+func (od OperationData) CallArgs() string {
+	args := make([]string, len(od.Params))
+	for i, p := range od.Params {
+		args[i] = p.Var
+	}
+	return strings.Join(args, ", ")
+}
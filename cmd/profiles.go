@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// SettingData is one environment variable the generated app reads.
+type SettingData struct {
+	Key string
+	// Local is its value in .env, for running on the host.
+	Local string
+	// Default is compiled into internal/config; empty for none.
+	Default string
+	// Shared settings keep their local value in every profile file; the
+	// others are left blank there, to be filled in per environment.
+	Shared   bool
+	Required bool
+}
+
+// ProfileData is a profile as the templates need it.
+type ProfileData struct {
+	Name   string
+	Values []EnvValue
+}
+
+// EnvValue is one line of a .env file.
+type EnvValue struct {
+	Key   string
+	Value string
+}
+
+// resolveSettings lists the settings of a project: the port of servers,
+// those of every datastore and those of the enabled features.
+func resolveSettings(port string, stores []StoreData, features FeaturesData) []SettingData {
+	var settings []SettingData
+	if port != "" {
+		settings = append(settings, SettingData{Key: "PORT", Local: port, Default: port, Shared: true, Required: true})
+	}
+
+	for _, store := range stores {
+		if store.Dialect == "sqlite" {
+			settings = append(settings, SettingData{Key: store.Prefix + "_DATABASE", Local: store.Database, Shared: true, Required: true})
+			continue
+		}
+		settings = append(settings,
+			SettingData{Key: store.Prefix + "_HOST", Local: "localhost", Required: true},
+			SettingData{Key: store.Prefix + "_PORT", Local: store.HostPort, Required: true},
+			SettingData{Key: store.Prefix + "_DATABASE", Local: store.Database, Shared: true, Required: true},
+			SettingData{Key: store.Prefix + "_USERNAME", Local: "example_username", Required: true},
+			SettingData{Key: store.Prefix + "_PASSWORD", Local: "password1234", Required: true},
+			SettingData{Key: store.Prefix + "_SCHEMA", Local: "public", Default: "public", Shared: true},
+		)
+	}
+
+	for _, feature := range features.All() {
+		settings = append(settings, SettingData{Key: feature.EnvKey, Local: feature.EnvValue, Required: true})
+	}
+	return settings
+}
+
+// resolveProfiles fills in the .env.<profile> file of every profile
+// declared in project.yaml. Values set under the profile's env win; extra
+// keys follow the generated ones, sorted.
+func resolveProfiles(yamlConfig *parser.Config, settings []SettingData) []ProfileData {
+	if yamlConfig == nil {
+		return nil
+	}
+
+	profiles := make([]ProfileData, 0, len(yamlConfig.Profiles))
+	for _, profile := range yamlConfig.Profiles {
+		pd := ProfileData{Name: profile.Name, Values: []EnvValue{{"APP_ENV", profile.Name}}}
+		known := map[string]bool{}
+		for _, setting := range settings {
+			known[setting.Key] = true
+			value, ok := profile.Env[setting.Key]
+			if !ok && setting.Shared {
+				value = setting.Local
+			}
+			pd.Values = append(pd.Values, EnvValue{setting.Key, value})
+		}
+
+		var extra []string
+		for key := range profile.Env {
+			if !known[key] {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(extra)
+		for _, key := range extra {
+			pd.Values = append(pd.Values, EnvValue{key, profile.Env[key]})
+		}
+		profiles = append(profiles, pd)
+	}
+	return profiles
+}
+
+// writeProfiles writes the .env.<profile> files of a project.
+func writeProfiles(projectDir string, profiles []ProfileData) error {
+	for _, profile := range profiles {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Settings of the %s profile, loaded when APP_ENV=%s.\n", profile.Name, profile.Name)
+		b.WriteString("# Blank values must be provided by the environment or with -set KEY=VALUE.\n")
+		for _, v := range profile.Values {
+			fmt.Fprintf(&b, "%s=%s\n", v.Key, envQuote(v.Value))
+		}
+		if err := os.WriteFile(filepath.Join(projectDir, ".env."+profile.Name), []byte(b.String()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envQuote quotes values that would otherwise be cut short or expanded by
+// .env parsers. Single quotes keep them literal when they allow it.
+func envQuote(value string) string {
+	if !strings.ContainsAny(value, " \t\n#'\"\\$") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(value) + `"`
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/framework"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRouter is used when neither the spec nor --router names one.
+const defaultRouter = "gin"
+
+// ProjectSettings is the effective configuration of a new project. Flags
+// override project.yaml, which overrides the defaults.
+type ProjectSettings struct {
+	Name     string
+	Location string // parent directory of the project
+	Type     string
+	Arch     string
+	Router   string
+	Port     string
+	DB       string
+	// Layout is the template set of Type and Arch.
+	Layout layout.ArchConfig
+}
+
+// Dir is the directory the project is written to.
+func (s ProjectSettings) Dir() string {
+	return filepath.Join(s.Location, s.Name)
+}
+
+// resolveProject merges the values set by flags with the project section
+// of the spec and fills in the defaults. Unknown types, architectures and
+// routers are rejected.
+func resolveProject(yamlConfig *parser.Config, flags ProjectSettings) (ProjectSettings, error) {
+	var spec parser.Project
+	if yamlConfig != nil {
+		spec = yamlConfig.Project
+	}
+	specPort := ""
+	if spec.Port != 0 {
+		specPort = strconv.Itoa(spec.Port)
+	}
+
+	s := ProjectSettings{
+		Name:     first(flags.Name, spec.Name),
+		Location: first(flags.Location, spec.Location, "."),
+		Type:     first(flags.Type, spec.Type, layout.DefaultType),
+		Router:   first(flags.Router, spec.Router),
+		Port:     first(flags.Port, specPort, "8080"),
+		DB:       first(flags.DB, spec.Database),
+	}
+	if s.Name == "" {
+		return s, fmt.Errorf("project name is required")
+	}
+
+	t, ok := layout.TypeRegistory[s.Type]
+	if !ok {
+		return s, fmt.Errorf("unknown project type %q (expected one of %s)", s.Type, strings.Join(layout.Types(), ", "))
+	}
+	defaultArch := t.DefaultArch
+	if yamlConfig != nil && len(yamlConfig.Modules) > 0 {
+		defaultArch = parser.ModularArch
+	}
+	s.Arch = first(flags.Arch, spec.Arch, defaultArch)
+	if s.Layout, ok = t.Archs[s.Arch]; !ok {
+		return s, fmt.Errorf("unknown arch %q for %s projects (expected one of %s)",
+			s.Arch, s.Type, strings.Join(layout.Archs(s.Type), ", "))
+	}
+	if yamlConfig != nil && len(yamlConfig.Modules) > 0 && s.Arch != parser.ModularArch {
+		return s, fmt.Errorf("modules need arch %q, not %q", parser.ModularArch, s.Arch)
+	}
+
+	if !t.Databases && s.DB != "" {
+		return s, fmt.Errorf("%s projects take no database, but %q is set", s.Type, s.DB)
+	}
+	if t.Healthcheck == nil {
+		// Only servers listen on a port.
+		if flags.Port != "" || specPort != "" {
+			return s, fmt.Errorf("%s projects take no port, but %s is set", s.Type, first(flags.Port, specPort))
+		}
+		s.Port = ""
+	}
+
+	if !t.HTTP {
+		if s.Router != "" {
+			return s, fmt.Errorf("%s projects take no router, but %q is set", s.Type, s.Router)
+		}
+	} else {
+		s.Router = first(s.Router, defaultRouter)
+		if _, ok := framework.FrameworkRegistory[s.Router]; !ok {
+			routers := make([]string, 0, len(framework.FrameworkRegistory))
+			for name := range framework.FrameworkRegistory {
+				routers = append(routers, name)
+			}
+			sort.Strings(routers)
+			return s, fmt.Errorf("unknown router %q (expected one of %s)", s.Router, strings.Join(routers, ", "))
+		}
+	}
+	if port, err := strconv.Atoi(s.Port); s.Port != "" && (err != nil || port < 1 || port > 65535) {
+		return s, fmt.Errorf("invalid port %q (expected 1-65535)", s.Port)
+	}
+	return s, nil
+}
+
+// first returns the first non-empty value.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// summary lists the settings, the datastores and the features a project is
+// generated with.
+func (s ProjectSettings) summary(stores []StoreData, features FeaturesData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project:   %s\n", s.Name)
+	fmt.Fprintf(&b, "Location:  %s\n", s.Dir())
+	fmt.Fprintf(&b, "Type:      %s\n", s.Type)
+	fmt.Fprintf(&b, "Arch:      %s\n", s.Arch)
+	if s.Router != "" {
+		fmt.Fprintf(&b, "Router:    %s\n", s.Router)
+	}
+	if s.Port != "" {
+		fmt.Fprintf(&b, "Port:      %s\n", s.Port)
+	}
+
+	dbs := make([]string, len(stores))
+	for i, store := range stores {
+		dbs[i] = store.Name + "=" + store.DB
+	}
+	fmt.Fprintf(&b, "Databases: %s\n", listOrNone(dbs))
+
+	var enabled []string
+	for _, feature := range features.All() {
+		enabled = append(enabled, feature.Kind+"="+feature.Provider)
+	}
+	fmt.Fprintf(&b, "Features:  %s\n", listOrNone(enabled))
+	return b.String()
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+// setting is one key of the project section of a spec.
+type setting struct {
+	Key   string
+	Value string
+}
+
+// overriddenSettings returns the project keys that flags set to a value the
+// spec does not have, in the order project.yaml lists them.
+func overriddenSettings(spec parser.Project, flags ProjectSettings) []setting {
+	specPort := ""
+	if spec.Port != 0 {
+		specPort = strconv.Itoa(spec.Port)
+	}
+
+	var pinned []setting
+	for _, s := range []struct{ key, flag, spec string }{
+		{"name", flags.Name, spec.Name},
+		{"type", flags.Type, spec.Type},
+		{"arch", flags.Arch, spec.Arch},
+		{"port", flags.Port, specPort},
+		{"router", flags.Router, spec.Router},
+		{"db", flags.DB, spec.Database},
+	} {
+		if s.flag != "" && s.flag != s.spec {
+			pinned = append(pinned, setting{s.key, s.flag})
+		}
+	}
+	return pinned
+}
+
+// pinSettings writes settings into the project section of the YAML spec at
+// path, so that it describes the project as it was generated. Comments are
+// kept; the file is left alone when there is nothing to write.
+func pinSettings(path string, settings []setting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping", path)
+	}
+
+	project := mappingValue(root, "project")
+	if project == nil {
+		project = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Value: "project"}, project}, root.Content...)
+	}
+	for _, s := range settings {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s.Value}
+		if s.Key == "port" {
+			value.Tag = "!!int"
+		}
+		if existing := mappingValue(project, s.Key); existing != nil {
+			// Keep the comments of the key.
+			value.HeadComment, value.LineComment = existing.HeadComment, existing.LineComment
+			*existing = *value
+		} else {
+			project.Content = append(project.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.Key}, value)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// mappingValue returns the value of key in the mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// promotableArch is the architecture projects are promoted from.
+const promotableArch = "minimal"
+
+// setAsideDir holds the files of the old layout that were edited, relative
+// to the project. Directories starting with a dot are ignored by go build.
+const setAsideDir = ".bootstrap/" + promotableArch
+
+var promoteTo string
+
+// promoteCmd moves a minimal project to another architecture.
+var promoteCmd = &cobra.Command{
+	Use:   "promote [dir]",
+	Short: "move a minimal project to another architecture.",
+	Long: `move a minimal project to another architecture.
+
+The project is generated again from its project.yaml, once as it is and once
+with the --to architecture, and the differences are applied to it. Files you
+have not edited are replaced or removed. Edited files are never lost: when
+the new layout has a file of the same name, yours is kept and the generated
+one is written next to it with a .promoted suffix; otherwise yours is moved
+to ` + setAsideDir + `/. Either way the logic in them is yours to move over.
+The directory defaults to the current one.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		return promoteProject(dir, promoteTo, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+	promoteCmd.Flags().StringVar(&promoteTo, "to", "clean", "architecture to promote the project to")
+}
+
+// promoteProject moves the minimal project in dir to the architecture to.
+func promoteProject(dir, to string, out io.Writer) error {
+	specPath := filepath.Join(dir, "project.yaml")
+	config, err := parser.ReadYAML(specPath)
+	if err != nil {
+		return err
+	}
+
+	projectType := first(config.Project.Type, layout.DefaultType)
+	from := first(config.Project.Arch, layout.TypeRegistory[projectType].DefaultArch)
+	if from != promotableArch {
+		return fmt.Errorf("%s is a %s project; only %s projects can be promoted", dir, from, promotableArch)
+	}
+	if _, ok := layout.Lookup(projectType, to); !ok || to == promotableArch {
+		var targets []string
+		for _, arch := range layout.Archs(projectType) {
+			if arch != promotableArch {
+				targets = append(targets, arch)
+			}
+		}
+		return fmt.Errorf("cannot promote to %q (expected one of %s)", to, strings.Join(targets, ", "))
+	}
+
+	tmp, err := os.MkdirTemp("", "bootstrap-promote-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// The project as generated tells apart the files you edited.
+	reference, err := generateArch(config.Project.Name, specPath, promotableArch, filepath.Join(tmp, "from"))
+	if err != nil {
+		return err
+	}
+	target, err := generateArch(config.Project.Name, specPath, to, filepath.Join(tmp, "to"))
+	if err != nil {
+		return err
+	}
+
+	written, removed, kept, err := reconcile(dir, reference, target)
+	if err != nil {
+		return err
+	}
+	for _, warning := range kept {
+		fmt.Fprintf(out, "warning: %s\n", warning)
+	}
+	fmt.Fprintf(out, "✓ Promoted '%s' to %s: %d files written, %d removed\n", config.Project.Name, to, written, removed)
+	return nil
+}
+
+// generateArch generates the project of the spec at specPath with arch
+// under location, as `bootstrap new --yaml <spec> --arch <arch>` would, and
+// returns its directory.
+func generateArch(name, specPath, arch, location string) (string, error) {
+	savedYAML, savedArch, savedLocation := YAMLPath, projectArch, projectLocation
+	savedDB, savedEntities, savedFeatures, savedPort := DBType, Entities, featureFlags, projectPort
+	defer func() {
+		YAMLPath, projectArch, projectLocation = savedYAML, savedArch, savedLocation
+		DBType, Entities, featureFlags, projectPort = savedDB, savedEntities, savedFeatures, savedPort
+	}()
+
+	YAMLPath, projectArch, projectLocation = specPath, arch, location
+	DBType, Entities, featureFlags, projectPort = "", nil, nil, ""
+
+	var log bytes.Buffer
+	if !createNewProject(name, "", "", &log) {
+		return "", fmt.Errorf("could not generate the %s layout:\n%s", arch, log.String())
+	}
+	return filepath.Join(location, name), nil
+}
+
+// reconcile applies to dir the differences between the reference and the
+// target projects. Files of dir that still match the reference are
+// replaced or removed; edited ones are kept, as described by the returned
+// warnings.
+func reconcile(dir, reference, target string) (written, removed int, kept []string, err error) {
+	refFiles, err := readTree(reference)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	targetFiles, err := readTree(target)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	paths := make([]string, 0, len(refFiles)+len(targetFiles))
+	for rel := range targetFiles {
+		paths = append(paths, rel)
+	}
+	for rel := range refFiles {
+		if _, ok := targetFiles[rel]; !ok {
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+
+	var gone []string
+
+	for _, rel := range paths {
+		ref, inRef := refFiles[rel]
+		want, inTarget := targetFiles[rel]
+		if inRef && inTarget && bytes.Equal(ref, want) {
+			continue
+		}
+
+		full := filepath.Join(dir, filepath.FromSlash(rel))
+		current, err := os.ReadFile(full)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return written, removed, kept, err
+		}
+		unedited := exists && inRef && bytes.Equal(current, ref)
+
+		switch {
+		case inTarget && exists && bytes.Equal(current, want):
+		case inTarget && (!exists || unedited):
+			if err := writeFile(full, want); err != nil {
+				return written, removed, kept, err
+			}
+			written++
+		case inTarget:
+			if err := writeFile(full+".promoted", want); err != nil {
+				return written, removed, kept, err
+			}
+			written++
+			kept = append(kept, fmt.Sprintf("kept your edited %s; the promoted one is %s.promoted", rel, rel))
+		case !exists:
+		case unedited:
+			if err := os.Remove(full); err != nil {
+				return written, removed, kept, err
+			}
+			gone = append(gone, rel)
+			removed++
+		default:
+			aside := filepath.Join(dir, filepath.FromSlash(setAsideDir), filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(aside), 0755); err != nil {
+				return written, removed, kept, err
+			}
+			if err := os.Rename(full, aside); err != nil {
+				return written, removed, kept, err
+			}
+			gone = append(gone, rel)
+			removed++
+			kept = append(kept, fmt.Sprintf("moved your edited %s to %s/%s", rel, setAsideDir, rel))
+		}
+	}
+	return written, removed, kept, pruneDirs(dir, gone)
+}
+
+// readTree reads every file under root, keyed by slash-separated path.
+func readTree(root string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// pruneDirs removes the directories of files, which are relative to root,
+// that removing the files left empty.
+func pruneDirs(root string, files []string) error {
+	for _, file := range files {
+		for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+			full := filepath.Join(root, filepath.FromSlash(dir))
+			entries, err := os.ReadDir(full)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // pruned for an earlier file
+			}
+			if err != nil {
+				return err
+			}
+			if len(entries) > 0 {
+				break
+			}
+			if err := os.Remove(full); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoteProject(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir))
+
+	spec := "project:\n  name: shop\n  arch: minimal\n  db: sqlite\nentities:\n  - user\n  - order\ncustom_logic:\n  user:\n    - { name: activate }\n"
+	require.NoError(t, os.WriteFile("spec.yaml", []byte(spec), 0644))
+
+	YAMLPath = "spec.yaml"
+	defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+	var out bytes.Buffer
+	require.True(t, createNewProject("", "", "", &out), out.String())
+	YAMLPath, DBType, Entities = "", "", nil
+
+	// Your edits and files are kept.
+	require.NoError(t, os.WriteFile(filepath.Join("shop", "user_custom.go"), []byte("package main\n\n// edited\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("shop", "Makefile"), []byte("edited\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("shop", "notes.md"), []byte("mine\n"), 0644))
+
+	out.Reset()
+	require.NoError(t, promoteProject("shop", "clean", &out))
+	assert.Contains(t, out.String(), "warning: kept your edited Makefile; the promoted one is Makefile.promoted\n")
+	assert.Contains(t, out.String(), "warning: moved your edited user_custom.go to .bootstrap/minimal/user_custom.go\n")
+	assert.Contains(t, out.String(), "✓ Promoted 'shop' to clean")
+
+	for _, file := range []string{
+		"cmd/main.go",
+		"internal/handler/user_handler.go",
+		"internal/service/user_custom.go",
+		"internal/model/order_model.go",
+		"Makefile.promoted",
+		".bootstrap/minimal/user_custom.go",
+		"notes.md",
+	} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.NoError(t, err, "Expected %s after promoting", file)
+	}
+	for _, file := range []string{"main.go", "user.go", "order.go", "user_custom.go", "seeds"} {
+		_, err := os.Stat(filepath.Join("shop", file))
+		assert.True(t, os.IsNotExist(err), "%s belongs to the minimal layout", file)
+	}
+
+	makefile, err := os.ReadFile(filepath.Join("shop", "Makefile"))
+	require.NoError(t, err)
+	assert.Equal(t, "edited\n", string(makefile))
+
+	promoted, err := os.ReadFile(filepath.Join("shop", "project.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(promoted), "arch: clean\n")
+
+	err = promoteProject("shop", "clean", &out)
+	assert.EqualError(t, err, "shop is a clean project; only minimal projects can be promoted")
+}
+
+func TestPromoteProject_UnknownTarget(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project.yaml"), []byte("project:\n  name: shop\n  arch: minimal\n"), 0644))
+
+	var out bytes.Buffer
+	err := promoteProject(dir, "minimal", &out)
+	assert.EqualError(t, err, `cannot promote to "minimal" (expected one of clean, hexagonal, modular)`)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/rpcgen"
+)
+
+// protoScalars maps the Go types of model fields to the proto3 scalars
+// carrying them.
+var protoScalars = map[string]struct{ Proto, Go string }{
+	"string":  {"string", "string"},
+	"int":     {"int64", "int64"},
+	"int64":   {"int64", "int64"},
+	"float64": {"double", "float64"},
+	"bool":    {"bool", "bool"},
+	"uint":    {"uint64", "uint64"}, // foreign keys
+}
+
+// ProtoFieldData is an entity field as the .proto and gRPC templates need it.
+type ProtoFieldData struct {
+	FieldData
+	Proto  string // name in the .proto
+	GoName string // name in the generated Go code
+	Type   string // proto type
+	// CreateLabel and UpdateLabel are "optional " on the fields whose
+	// messages must tell an unset value from a zero one.
+	CreateLabel string
+	UpdateLabel string
+	// ToProto converts the model field, and ToCreate and ToUpdate the fields
+	// of create and update requests to the DTOs'; %s is the value.
+	ToProto  string
+	ToCreate string
+	ToUpdate string
+}
+
+// protoFields prepares fields for the gRPC templates.
+func protoFields(fields []FieldData) []ProtoFieldData {
+	out := make([]ProtoFieldData, 0, len(fields))
+	for _, fd := range fields {
+		pf := ProtoFieldData{FieldData: fd, Proto: fd.Column, GoName: rpcgen.GoName(fd.Column)}
+		nullable := strings.HasPrefix(fd.Type, "*")
+
+		switch fd.Spec.Type {
+		case "time":
+			pf.Type = "google.protobuf.Timestamp"
+			pf.ToProto, pf.ToCreate = "toTimestamp(%s)", "fromTimestamp(%s)"
+			if nullable {
+				pf.ToProto, pf.ToCreate = "toTimestampPtr(%s)", "fromTimestampPtr(%s)"
+			}
+			pf.ToUpdate = "fromTimestampPtr(%s)"
+		case "json":
+			pf.Type = "string"
+			pf.UpdateLabel = "optional "
+			pf.ToProto, pf.ToCreate, pf.ToUpdate = "string(%s)", "fromJSON(%s)", "fromJSONPtr(%s)"
+		case "bytes":
+			pf.Type = "bytes"
+			pf.ToProto, pf.ToCreate, pf.ToUpdate = "%s", "%s", "%s"
+		default:
+			goType := strings.TrimPrefix(fd.Type, "*")
+			scalar := protoScalars[goType]
+			pf.Type = scalar.Proto
+			pf.UpdateLabel = "optional "
+			if nullable {
+				pf.CreateLabel = "optional "
+			}
+
+			switch {
+			case scalar.Go == goType:
+				pf.ToProto, pf.ToCreate, pf.ToUpdate = "%s", "%s", "%s"
+			case nullable:
+				pf.ToProto, pf.ToCreate = "convertPtr["+scalar.Go+"](%s)", "convertPtr["+goType+"](%s)"
+				pf.ToUpdate = pf.ToCreate
+			default:
+				pf.ToProto, pf.ToCreate = scalar.Go+"(%s)", goType+"(%s)"
+				pf.ToUpdate = "convertPtr[" + goType + "](%s)"
+			}
+		}
+		out = append(out, pf)
+	}
+	return out
+}
+
+// protoHelpers lists the conversion helpers the fields of every entity call,
+// so that the gRPC templates only declare those.
+func protoHelpers(entities []string, entityFields map[string][]FieldData) map[string]bool {
+	used := map[string]bool{}
+	for _, entity := range entities {
+		for _, pf := range protoFields(entityFields[entity]) {
+			addHelpers(used, pf.ToProto, pf.ToCreate, pf.ToUpdate)
+		}
+	}
+	return used
+}
+
+// protoImports are the files the service file of an entity imports: its own
+// message file, those of the entities it links to many-to-many, and the
+// well-known types it uses.
+func protoImports(entity string, fields []ProtoFieldData, manyToMany []AssociationData) []string {
+	seen := map[string]bool{
+		"api/v1/" + strings.ToLower(entity) + ".proto": true,
+		"google/protobuf/empty.proto":                  true,
+	}
+	for _, assoc := range manyToMany {
+		seen["api/v1/"+strings.ToLower(assoc.TargetLower)+".proto"] = true
+	}
+	for _, pf := range fields {
+		if pf.Type == "google.protobuf.Timestamp" {
+			seen["google/protobuf/timestamp.proto"] = true
+		}
+	}
+
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// writeProtoCode generates the Go code of the .proto files under protoDir
+// in the project, as `make proto` does with protoc.
+func writeProtoCode(projectDir, protoDir, module string) error {
+	root := filepath.Join(projectDir, filepath.FromSlash(protoDir))
+	fsys := os.DirFS(root)
+
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, ".proto") {
+			paths = append(paths, path)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	files, err := rpcgen.Generate(fsys, paths, module)
+	if err != nil {
+		return err
+	}
+	for path, content := range files {
+		target := filepath.Join(projectDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import "github.com/upsaurav12/bootstrap/pkg/parser"
+
+// realtimeDir holds the event bus and the stream handlers, rendered into
+// internal/ of projects with realtime entities, along with the adapters of
+// the architecture's Layout.RealtimeDir.
+const realtimeDir = "realtime/shared"
+
+// resolveRealtime returns the entities declared realtime in project.yaml,
+// keyed by the entity names used for generation.
+func resolveRealtime(yamlConfig *parser.Config, entities []string) map[string]bool {
+	realtime := map[string]bool{}
+	if yamlConfig == nil {
+		return realtime
+	}
+	for _, entity := range entities {
+		if spec, ok := yamlConfig.Entity(entity); ok && spec.Realtime {
+			realtime[entity] = true
+		}
+	}
+	return realtime
+}
+
+// markRealtimeModules flags the modules that hold realtime entities, whose
+// constructors then take the bus and the streams.
+func markRealtimeModules(modules []ModuleData, realtime map[string]bool) {
+	for i, module := range modules {
+		for _, entity := range module.Entities {
+			if realtime[entity] {
+				modules[i].Realtime = true
+			}
+		}
+	}
+}
+
+// realtimeSettings are the settings of the event bus and the streams of
+// projects with realtime entities.
+func realtimeSettings() []SettingData {
+	return []SettingData{
+		{Key: "REALTIME_BUFFER", Local: "64", Default: "64", Shared: true},
+		{Key: "REALTIME_HEARTBEAT", Local: "15s", Default: "15s", Shared: true},
+	}
+}
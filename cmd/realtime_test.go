@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+func TestResolveRealtime(t *testing.T) {
+	config := &parser.Config{Entities: []parser.Entity{
+		{Name: "user", Realtime: true},
+		{Name: "order"},
+	}}
+
+	tests := []struct {
+		name     string
+		config   *parser.Config
+		entities []string
+		want     map[string]bool
+	}{
+		{"no spec", nil, []string{"user"}, map[string]bool{}},
+		{"realtime entity", config, []string{"user", "order"}, map[string]bool{"user": true}},
+		{"entity from flags", config, []string{"product"}, map[string]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveRealtime(tt.config, tt.entities))
+		})
+	}
+}
+
+// generateRealtime generates a project of arch with user realtime and order
+// not, on router.
+func generateRealtime(t *testing.T, arch, router string) {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	t.Cleanup(func() { os.Chdir(oldDir) })
+	require.NoError(t, os.Chdir(tempDir), "Failed to change to temp directory")
+
+	spec := `project:
+  name: feed
+  arch: ` + arch + `
+  router: ` + router + `
+  db: postgres
+entities:
+  - name: user
+    realtime: true
+  - order
+`
+	require.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+	YAMLPath = "project.yaml"
+	t.Cleanup(func() { YAMLPath, DBType, Entities = "", "", nil })
+
+	var out bytes.Buffer
+	require.True(t, createNewProject("", "", "", &out), out.String())
+}
+
+func TestCreateNewProject_Realtime(t *testing.T) {
+	generateRealtime(t, "clean", "chi")
+
+	for _, file := range []string{
+		"internal/events/bus.go",
+		"internal/events/bus_test.go",
+		"internal/realtime/stream.go",
+		"internal/realtime/stream_test.go",
+	} {
+		_, err := os.Stat(filepath.Join("feed", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+
+	users, err := os.ReadFile(filepath.Join("feed", "internal/service/user_service.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(users), "s.events.Publish(events.Event{Entity: \"user\", Action: events.Created, ID: user.ID, Data: *user})")
+	assert.Contains(t, string(users), "s.events.Publish(events.Event{Entity: \"user\", Action: events.Deleted, ID: id})")
+
+	orders, err := os.ReadFile(filepath.Join("feed", "internal/service/order_service.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(orders), "events", "order is not realtime")
+
+	routes, err := os.ReadFile(filepath.Join("feed", "internal/server/routes.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(routes), `r.Handle("/api/v1/users/stream", s.streams.Handler("user"))`)
+	assert.NotContains(t, string(routes), "/api/v1/orders/stream")
+
+	server, err := os.ReadFile(filepath.Join("feed", "internal/server/server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(server), "httpServer.RegisterOnShutdown(bus.Close)")
+
+	env, err := os.ReadFile(filepath.Join("feed", ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "REALTIME_BUFFER=64")
+	assert.Contains(t, string(env), "REALTIME_HEARTBEAT=15s")
+
+	spec, err := os.ReadFile(filepath.Join("feed", "project.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), "  - name: user\n    realtime: true\n")
+}
+
+func TestCreateNewProject_RealtimeBuffered(t *testing.T) {
+	generateRealtime(t, "clean", "fiber")
+
+	routes, err := os.ReadFile(filepath.Join("feed", "internal/server/routes.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(routes), "/stream", "fiber buffers responses, so streams bypass it")
+
+	server, err := os.ReadFile(filepath.Join("feed", "internal/server/server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(server), "handler = streams.Mount(handler, map[string]string{")
+	assert.Contains(t, string(server), `"/api/v1/users/stream": "user",`)
+}
+
+func TestCreateNewProject_RealtimeHexagonal(t *testing.T) {
+	generateRealtime(t, "hexagonal", "chi")
+
+	for _, file := range []string{
+		"internal/events/bus.go",
+		"internal/core/ports/events.go",
+		"internal/adapters/eventbus/publisher.go",
+		"internal/adapters/eventbus/publisher_test.go",
+	} {
+		_, err := os.Stat(filepath.Join("feed", file))
+		assert.NoError(t, err, "Expected %s to be generated", file)
+	}
+
+	users, err := os.ReadFile(filepath.Join("feed", "internal/core/services/user_service.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(users), "internal/events", "the core only knows the port")
+	assert.Contains(t, string(users), "func NewUserService(repo ports.UserRepository, publisher ports.EventPublisher) ports.UserService {")
+	assert.Contains(t, string(users), `s.events.Publish(ports.Event{Entity: "user", Action: ports.Created, ID: user.ID, Data: *user})`)
+
+	server, err := os.ReadFile(filepath.Join("feed", "internal/adapters/http/server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(server), "events:  eventbus.NewPublisher(bus),")
+}
+
+func TestCreateNewProject_RealtimeHexagonalWithoutEntities(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir), "Failed to change to temp directory")
+
+	projectArch = "hexagonal"
+	Entities = []string{"user"}
+	defer func() { projectArch, Entities = "", nil }()
+
+	var out bytes.Buffer
+	require.True(t, createNewProject("plain", "", "", &out), out.String())
+
+	for _, dir := range []string{"internal/adapters/eventbus", "internal/core/ports/events.go"} {
+		_, err = os.Stat(filepath.Join("plain", dir))
+		assert.True(t, os.IsNotExist(err), "projects without realtime entities have no %s", dir)
+	}
+}
+
+func TestCreateNewProject_RealtimeWithoutEntities(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err, "Failed to get working directory")
+	defer os.Chdir(oldDir)
+	require.NoError(t, os.Chdir(tempDir), "Failed to change to temp directory")
+
+	var out bytes.Buffer
+	require.True(t, createNewProject("plain", "", "", &out), out.String())
+
+	_, err = os.Stat(filepath.Join("plain", "internal/events"))
+	assert.True(t, os.IsNotExist(err), "projects without realtime entities have no bus")
+	env, err := os.ReadFile(filepath.Join("plain", ".env"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(env), "REALTIME_")
+}
+
+func TestCreateNewProject_RealtimeTypeByFlag(t *testing.T) {
+	for _, projectType := range []string{"grpc", "graphql"} {
+		t.Run(projectType, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			oldDir, err := os.Getwd()
+			require.NoError(t, err, "Failed to get working directory")
+			defer os.Chdir(oldDir)
+			require.NoError(t, os.Chdir(tempDir), "Failed to change to temp directory")
+
+			// The spec's own type is rest, which takes realtime entities;
+			// the flag overrides it.
+			spec := "project:\n  name: feed\nentities:\n  - name: customer\n    realtime: true\n"
+			require.NoError(t, os.WriteFile("project.yaml", []byte(spec), 0644))
+			YAMLPath = "project.yaml"
+			defer func() { YAMLPath, DBType, Entities = "", "", nil }()
+
+			var out bytes.Buffer
+			assert.False(t, createNewProject("", "", projectType, &out))
+			assert.Contains(t, out.String(), "Error: "+projectType+" projects take no realtime entities\n")
+			_, err = os.Stat("feed")
+			assert.True(t, os.IsNotExist(err), "nothing is written for a rejected project")
+		})
+	}
+}
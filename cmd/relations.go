@@ -0,0 +1,250 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// ForeignKeyData is a link from an entity to the parent it belongs to.
+type ForeignKeyData struct {
+	Field       FieldData
+	Parent      string // Go type of the parent, e.g. User
+	ParentLower string // entity name of the parent
+	Required    bool
+	// Method names the "list children of a parent" operation, e.g.
+	// GetOrdersByUser, and Path is its nested route.
+	Method string
+	Path   string
+}
+
+// AssociationData is a GORM association on an entity's model.
+type AssociationData struct {
+	Kind        string
+	Field       string // e.g. Orders
+	JSON        string
+	Target      string // Go type of the target, e.g. Order
+	TargetLower string // entity name of the target
+	Tag         string
+	// ForeignKey is the key linking the two sides of belongs_to and
+	// has_many associations, which the child entity holds.
+	ForeignKey ForeignKeyData
+	// Many-to-many only: the IDs accepted in request bodies, the join
+	// table and the nested route listing linked rows.
+	IDsField  string
+	IDsJSON   string
+	JoinTable string
+	Method    string
+	Path      string
+}
+
+// RelationData gathers everything an entity's templates need to know about
+// its relations.
+type RelationData struct {
+	ForeignKeys  []ForeignKeyData
+	Associations []AssociationData
+}
+
+// entityType is the Go type generated for an entity name.
+func entityType(entity string) string {
+	return returnUppercase(entity)
+}
+
+// canonicalEntity resolves a relation target to the entity name used for
+// generation, so that "User" and "user" refer to the same entity.
+func canonicalEntity(entities []string, name string) string {
+	for _, entity := range entities {
+		if strings.EqualFold(entity, name) {
+			return entity
+		}
+	}
+	return name
+}
+
+// resolveRelations turns the relations: declared in project.yaml into
+// foreign keys and associations per entity. belongs_to and has_many
+// declarations of the same foreign key are merged.
+func resolveRelations(yamlConfig *parser.Config, entities []string) map[string]*RelationData {
+	out := make(map[string]*RelationData, len(entities))
+	for _, entity := range entities {
+		out[entity] = &RelationData{}
+	}
+	if yamlConfig == nil {
+		return out
+	}
+
+	// addForeignKey records column on child, keeping the strictest
+	// requirement when both sides declare it.
+	addForeignKey := func(child, parent, column string, required bool) {
+		rd := out[child]
+		for i := range rd.ForeignKeys {
+			if rd.ForeignKeys[i].Field.Column == column {
+				rd.ForeignKeys[i].Required = rd.ForeignKeys[i].Required || required
+				return
+			}
+		}
+		rd.ForeignKeys = append(rd.ForeignKeys, ForeignKeyData{
+			Field:       FieldData{Name: naming.Pascal(column), JSON: column, Column: column},
+			Parent:      entityType(parent),
+			ParentLower: parent,
+			Required:    required,
+		})
+	}
+
+	for _, spec := range yamlConfig.Entities {
+		owner := canonicalEntity(entities, spec.Name)
+		for _, rel := range spec.Relations {
+			target := canonicalEntity(entities, rel.Entity)
+			assoc := AssociationData{
+				Kind:        rel.Type,
+				Field:       rel.FieldName(),
+				Target:      entityType(target),
+				TargetLower: target,
+			}
+			assoc.JSON = naming.Snake(assoc.Field)
+
+			switch rel.Type {
+			case parser.BelongsTo:
+				column := rel.ForeignKeyColumn(spec.Name)
+				addForeignKey(owner, target, column, rel.Required)
+				assoc.ForeignKey.Field.Column = column
+				assoc.Tag = fmt.Sprintf(`gorm:"foreignKey:%s" json:"%s,omitempty"`, naming.Pascal(column), assoc.JSON)
+			case parser.HasMany:
+				column := rel.ForeignKeyColumn(spec.Name)
+				addForeignKey(target, owner, column, false)
+				assoc.ForeignKey.Field.Column = column
+				assoc.Tag = fmt.Sprintf(`gorm:"foreignKey:%s" json:"%s,omitempty"`, naming.Pascal(column), assoc.JSON)
+			case parser.ManyToMany:
+				assoc.JoinTable = rel.JoinTableName(spec.Name)
+				assoc.Tag = fmt.Sprintf(`gorm:"many2many:%s" json:"%s,omitempty"`, assoc.JoinTable, assoc.JSON)
+				singular := naming.Singular(assoc.Field)
+				assoc.IDsField = singular + "IDs"
+				assoc.IDsJSON = naming.Snake(singular) + "_ids"
+				assoc.Method = "Get" + entityType(owner) + assoc.Field
+				assoc.Path = fmt.Sprintf("/api/v1/%s/{id}/%s", resourcePath(owner), strings.ReplaceAll(assoc.JSON, "_", "-"))
+			}
+			out[owner].Associations = append(out[owner].Associations, assoc)
+		}
+	}
+
+	for _, entity := range entities {
+		rd := out[entity]
+		used := map[string]bool{}
+		for i := range rd.ForeignKeys {
+			fk := &rd.ForeignKeys[i]
+			fk.Field.Type = "*uint"
+			fk.Field.UpdateType = "*uint"
+			settings := "column:" + fk.Column() + ";index"
+			if fk.Required {
+				fk.Field.Type = "uint"
+				fk.Field.Deref = true
+				fk.Field.CreateRules = "required"
+				fk.Field.UpdateRules = "omitempty,nonzero"
+				settings = "column:" + fk.Column() + ";not null;index"
+			}
+			fk.Field.Tag = `gorm:"` + settings + `" json:"` + fk.Field.JSON + `"`
+
+			// The first link to a parent is listed under the parent's
+			// path; further ones are told apart by their key.
+			role := strings.TrimSuffix(fk.Field.Name, "ID")
+			fk.Method = "Get" + entityType(entity) + "sBy" + role
+			fk.Path = fmt.Sprintf("/api/v1/%s/{id}/%s", resourcePath(fk.ParentLower), resourcePath(entity))
+			if used[fk.ParentLower] {
+				fk.Path += "/" + strings.ReplaceAll(naming.Snake(role), "_", "-")
+			}
+			used[fk.ParentLower] = true
+		}
+	}
+
+	// Associations get the keys they were declared with once those are
+	// complete.
+	for _, entity := range entities {
+		for i := range out[entity].Associations {
+			assoc := &out[entity].Associations[i]
+			child := entity
+			switch assoc.Kind {
+			case parser.HasMany:
+				child = assoc.TargetLower
+			case parser.ManyToMany:
+				continue
+			}
+			for _, fk := range out[child].ForeignKeys {
+				if fk.Column() == assoc.ForeignKey.Column() {
+					assoc.ForeignKey = fk
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// ManyToMany returns the many_to_many associations.
+func (rd *RelationData) ManyToMany() []AssociationData {
+	var out []AssociationData
+	for _, assoc := range rd.Associations {
+		if assoc.Kind == parser.ManyToMany {
+			out = append(out, assoc)
+		}
+	}
+	return out
+}
+
+// Column is the database column of the foreign key.
+func (fk ForeignKeyData) Column() string {
+	return fk.Field.Column
+}
+
+// checkRelationStores rejects relations between entities kept in different
+// datastores, which no database can enforce.
+func checkRelationStores(entities []string, relations map[string]*RelationData, entityStore map[string]string) error {
+	for _, entity := range entities {
+		rd := relations[entity]
+		for _, fk := range rd.ForeignKeys {
+			if entityStore[entity] != entityStore[fk.ParentLower] {
+				return fmt.Errorf("entity %q (store %q) cannot relate to %q (store %q); related entities must share a store",
+					entity, entityStore[entity], fk.ParentLower, entityStore[fk.ParentLower])
+			}
+		}
+		for _, assoc := range rd.Associations {
+			if entityStore[entity] != entityStore[assoc.TargetLower] {
+				return fmt.Errorf("entity %q (store %q) cannot relate to %q (store %q); related entities must share a store",
+					entity, entityStore[entity], assoc.TargetLower, entityStore[assoc.TargetLower])
+			}
+		}
+	}
+	return nil
+}
+
+// orderEntities sorts entities so that parents come before the entities
+// that belong to them, keeping the declared order otherwise. Tables are
+// migrated and seeded in this order.
+func orderEntities(entities []string, relations map[string]*RelationData) []string {
+	ordered := make([]string, 0, len(entities))
+	state := map[string]int{}
+
+	var visit func(entity string)
+	visit = func(entity string) {
+		if state[entity] != 0 {
+			return
+		}
+		state[entity] = 1
+		for _, fk := range relations[entity].ForeignKeys {
+			if _, ok := relations[fk.ParentLower]; ok {
+				visit(fk.ParentLower)
+			}
+		}
+		state[entity] = 2
+		ordered = append(ordered, entity)
+	}
+
+	for _, entity := range entities {
+		visit(entity)
+	}
+	return ordered
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// specCmd groups the commands that work on project specs.
+var specCmd = &cobra.Command{
+	Use:   "spec",
+	Short: "inspect and transform project specs.",
+	Long:  `inspect and transform project specs.`,
+}
+
+// specResolveCmd prints a spec merged with every spec it extends.
+var specResolveCmd = &cobra.Command{
+	Use:   "resolve [file]",
+	Short: "print a project spec merged with the specs it extends.",
+	Long: `print a project spec merged with the specs it extends.
+
+The spec is followed through its extends chain and merged: maps merge key by
+key, lists replace the inherited ones unless tagged !append, and the nearest
+file wins. The result is validated like 'bootstrap validate' and printed as
+the project gets it, as YAML. The file defaults to the project spec of the
+current directory.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := specPath(args)
+		if _, err := parser.ReadYAML(path); err != nil {
+			return err
+		}
+
+		resolved, err := parser.ResolveYAML(path)
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(resolved)
+		return err
+	},
+}
+
+var (
+	convertTo  string
+	convertOut string
+)
+
+// specConvertCmd rewrites a spec in another format.
+var specConvertCmd = &cobra.Command{
+	Use:   "convert [file]",
+	Short: "rewrite a project spec as JSON, YAML or TOML.",
+	Long: `rewrite a project spec as JSON, YAML or TOML.
+
+The spec is resolved and validated first, then written in the --to format,
+to stdout or --out. The result decodes to the same project: converting it
+back gives the same spec. Comments and the extends chain are not kept, and
+TOML has no null, so specs holding null seed values cannot be written as
+TOML. The file defaults to the project spec of the current directory.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := specPath(args)
+		config, err := parser.ReadYAML(path)
+		if err != nil {
+			return err
+		}
+
+		out, err := parser.Encode(config, convertTo)
+		if err != nil {
+			return err
+		}
+		if convertOut == "" {
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		}
+		if err := os.WriteFile(convertOut, out, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Wrote %s\n", convertOut)
+		return nil
+	},
+}
+
+// specFiles are the names a project spec is looked up under, in order.
+var specFiles = []string{"project.yaml", "project.yml", "project.json", "project.toml"}
+
+// specPath returns the spec named in args or, without one, the first spec
+// file found in the current directory.
+func specPath(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	for _, name := range specFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return specFiles[0]
+}
+
+func init() {
+	rootCmd.AddCommand(specCmd)
+	specCmd.AddCommand(specResolveCmd)
+	specCmd.AddCommand(specConvertCmd)
+
+	specConvertCmd.Flags().StringVar(&convertTo, "to", parser.FormatYAML, "format to write: "+strings.Join(parser.Formats, ", "))
+	specConvertCmd.Flags().StringVar(&convertOut, "out", "", "file to write instead of stdout")
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/addons"
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// primaryStore is the datastore used by entities that do not pick one, and
+// the name given to the single database of `--db` / `project.db`.
+const primaryStore = "primary"
+
+// StoreData describes one datastore of the generated project.
+type StoreData struct {
+	Name     string // key under databases: in project.yaml
+	DB       string // key in addons.DbRegistory
+	Dialect  string
+	Prefix   string // environment variable prefix, e.g. GONE_ANALYTICS_DB
+	HostPort string // port published on the host by docker-compose
+	Database string // database name, or file name for SQLite
+	Config   addons.DbAddOneConfig
+}
+
+// storePrefix keeps the historical GONE_DB_* variables for the primary
+// store and namespaces every other store as GONE_<NAME>_DB_*.
+func storePrefix(name string) string {
+	if name == primaryStore {
+		return "GONE_DB"
+	}
+	return "GONE_" + strings.ToUpper(naming.Snake(name)) + "_DB"
+}
+
+// resolveStores turns the databases: map (or the single --db / project.db
+// value) into datastores, and binds every entity to one of them.
+func resolveStores(yamlConfig *parser.Config, dbType string, entities []string) ([]StoreData, map[string]string, error) {
+	declared := map[string]string{}
+	if yamlConfig != nil {
+		for name, store := range yamlConfig.Databases {
+			declared[name] = store.Database
+		}
+	}
+	if len(declared) == 0 && dbType != "" {
+		declared[primaryStore] = dbType
+	}
+
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == primaryStore) != (names[j] == primaryStore) {
+			return names[i] == primaryStore
+		}
+		return names[i] < names[j]
+	})
+
+	var stores []StoreData
+	published := 0
+	for _, name := range names {
+		db := declared[name]
+		cfg, ok := addons.DbRegistory[db]
+		if !ok {
+			return nil, nil, fmt.Errorf("store %q: unknown database %q", name, db)
+		}
+		if cfg.Dialect == "" {
+			return nil, nil, fmt.Errorf("store %q: %s is not supported by the generated repositories", name, db)
+		}
+
+		store := StoreData{
+			Name:     name,
+			DB:       db,
+			Dialect:  cfg.Dialect,
+			Prefix:   storePrefix(name),
+			Database: "gone",
+		}
+		if name != primaryStore {
+			store.Database = naming.Snake(name)
+		}
+		store.Config = cfg.ForStore(name, store.Prefix)
+
+		if cfg.Dialect == "sqlite" {
+			store.Database += ".db"
+		} else {
+			// Publish each store one port below the previous so that two
+			// stores of the same kind do not clash on the host.
+			containerPort, _ := strconv.Atoi(cfg.Port)
+			published++
+			store.HostPort = strconv.Itoa(containerPort - published)
+		}
+
+		stores = append(stores, store)
+	}
+
+	entityStore := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		var want string
+		if yamlConfig != nil {
+			if spec, ok := yamlConfig.Entity(entity); ok {
+				want = spec.Store
+			}
+		}
+
+		switch {
+		case want != "":
+			if _, ok := declared[want]; !ok {
+				return nil, nil, fmt.Errorf("entity %q uses store %q, which is not declared under databases", entity, want)
+			}
+		case len(stores) == 0:
+		case declared[primaryStore] != "":
+			want = primaryStore
+		case len(stores) == 1:
+			want = stores[0].Name
+		default:
+			return nil, nil, fmt.Errorf("entity %q must choose a store (there is no %q store to default to)", entity, primaryStore)
+		}
+		entityStore[entity] = want
+	}
+
+	return stores, entityStore, nil
+}
+
+// storeDialects reports which GORM dialects the stores need.
+func storeDialects(stores []StoreData) map[string]bool {
+	dialects := map[string]bool{}
+	for _, store := range stores {
+		dialects[store.Dialect] = true
+	}
+	return dialects
+}
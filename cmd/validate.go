@@ -0,0 +1,48 @@
+/*
+Copyright © 2025 Saurav Upadhyay sauravup041103@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+	"github.com/upsaurav12/bootstrap/schema"
+)
+
+var validateSchema bool
+
+// validateCmd checks a project.yaml without generating anything.
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "check a project.yaml for mistakes.",
+	Long: `check a project.yaml for mistakes.
+
+Unknown keys, values of the wrong type, unknown routers and databases,
+invalid entity names, bad fields and relations are all reported with their
+line and column. JSON and TOML specs are checked alike. The file defaults
+to the project spec of the current directory; --schema prints the
+JSON Schema of the file instead, for editor autocompletion.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if validateSchema {
+			_, err := cmd.OutOrStdout().Write(schema.Project)
+			return err
+		}
+
+		path := specPath(args)
+		if _, err := parser.ReadYAML(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ %s is valid\n", path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().BoolVar(&validateSchema, "schema", false, "print the JSON Schema of project.yaml")
+}
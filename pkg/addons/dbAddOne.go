@@ -1,32 +1,111 @@
 package addons
 
+import (
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/compose"
+)
+
 type DbAddOneConfig struct {
 	ServiceName string
 	Image       string
-	Environment string
+	Command     []string
+	Environment map[string]string
+	Healthcheck *compose.Healthcheck
 	Port        string
 	Volume      string
 	VolumeName  string
 	DBName      string
 	DBEnvPrefix string
+	Dialect     string // GORM dialect family, empty when GORM cannot use it
 	Import      string
 	Driver      string
 	DSN         string
 	OutputFile  string
 }
 
+// Fragment returns the docker-compose service for the database. Embedded
+// databases such as SQLite have no service and return false.
+func (c DbAddOneConfig) Fragment() (compose.Fragment, bool) {
+	if c.Image == "" {
+		return compose.Fragment{}, false
+	}
+
+	svc := compose.Service{
+		Image:       c.Image,
+		Command:     c.Command,
+		Restart:     "unless-stopped",
+		Environment: c.Environment,
+		Ports:       []string{"${" + c.DBEnvPrefix + "_PORT}:" + c.Port},
+		Healthcheck: c.Healthcheck,
+	}
+
+	var volumes []string
+	if c.Volume != "" {
+		svc.Volumes = []string{c.Volume}
+		volumes = []string{c.VolumeName}
+	}
+
+	return compose.Fragment{Name: c.ServiceName, Service: svc, Volumes: volumes}, true
+}
+
+// ForStore adapts the config to the named datastore: the compose service
+// and volume are prefixed with the store name and environment references
+// use the store's variable prefix. The primary store keeps the defaults.
+func (c DbAddOneConfig) ForStore(store, prefix string) DbAddOneConfig {
+	if store != "primary" {
+		if c.ServiceName != "" {
+			c.ServiceName = store + "_" + c.ServiceName
+		}
+		if c.VolumeName != "" {
+			c.Volume = store + "_" + c.Volume
+			c.VolumeName = store + "_" + c.VolumeName
+		}
+	}
+
+	env := make(map[string]string, len(c.Environment))
+	for key, value := range c.Environment {
+		env[key] = strings.ReplaceAll(value, "${"+c.DBEnvPrefix+"_", "${"+prefix+"_")
+	}
+	c.Environment = env
+	c.DBEnvPrefix = prefix
+
+	return c
+}
+
+// Databases lists, sorted, the databases of DbRegistory that projects can be
+// generated for: the ones GORM has a dialect for.
+func Databases() []string {
+	var names []string
+	for _, name := range sortedNames(DbRegistory) {
+		if DbRegistory[name].Dialect != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 var DbRegistory = map[string]DbAddOneConfig{
 	"postgres": {
 		ServiceName: "postgres_bp",
-		Image:       "postgres:latest",
-		Environment: `      POSTGRES_DB: ${GONE_DB_DATABASE}
-      POSTGRES_USER: ${GONE_DB_USERNAME}
-      POSTGRES_PASSWORD: ${GONE_DB_PASSWORD}`,
+		Image:       "postgres:16",
+		Environment: map[string]string{
+			"POSTGRES_DB":       "${GONE_DB_DATABASE}",
+			"POSTGRES_USER":     "${GONE_DB_USERNAME}",
+			"POSTGRES_PASSWORD": "${GONE_DB_PASSWORD}",
+		},
+		Healthcheck: &compose.Healthcheck{
+			Test:     []string{"CMD-SHELL", "pg_isready -U $${POSTGRES_USER} -d $${POSTGRES_DB}"},
+			Interval: "5s",
+			Timeout:  "5s",
+			Retries:  10,
+		},
 		Port:        "5432",
 		Volume:      "postgres_volume_bp:/var/lib/postgresql/data",
 		VolumeName:  "postgres_volume_bp",
 		DBName:      "PostgreSQL",
-		DBEnvPrefix: "BLUEPRINT",
+		DBEnvPrefix: "GONE_DB",
+		Dialect:     "postgres",
 		Import:      `_ "github.com/jackc/pgx/v5/stdlib"`,
 		Driver:      "pgx",
 		DSN:         "postgres://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s",
@@ -35,16 +114,25 @@ var DbRegistory = map[string]DbAddOneConfig{
 	"mysql": {
 		ServiceName: "mysql_bp",
 		Image:       "mysql:8",
-		Environment: `
-      MYSQL_DATABASE: ${GONE_DB_DATABASE}
-      MYSQL_USER: ${GONE_DB_USERNAME}
-      MYSQL_PASSWORD: ${GONE_DB_PASSWORD}
-      MYSQL_ROOT_PASSWORD: ${GONE_DB_PASSWORD}`,
+		Environment: map[string]string{
+			"MYSQL_DATABASE":      "${GONE_DB_DATABASE}",
+			"MYSQL_USER":          "${GONE_DB_USERNAME}",
+			"MYSQL_PASSWORD":      "${GONE_DB_PASSWORD}",
+			"MYSQL_ROOT_PASSWORD": "${GONE_DB_PASSWORD}",
+		},
+		Healthcheck: &compose.Healthcheck{
+			Test:        []string{"CMD", "mysqladmin", "ping", "-h", "localhost"},
+			Interval:    "5s",
+			Timeout:     "5s",
+			Retries:     10,
+			StartPeriod: "20s",
+		},
 		Port:        "3306",
 		Volume:      "mysql_volume_bp:/var/lib/mysql",
 		VolumeName:  "mysql_volume_bp",
 		DBName:      "MySQL",
-		DBEnvPrefix: "BLUEPRINT",
+		DBEnvPrefix: "GONE_DB",
+		Dialect:     "mysql",
 		Import:      `_ "github.com/go-sql-driver/mysql"`,
 		Driver:      "mysql",
 		DSN:         "%s:%s@tcp(%s:%s)/%s",
@@ -53,26 +141,32 @@ var DbRegistory = map[string]DbAddOneConfig{
 
 	"mongodb": {
 		ServiceName: "mongo_bp",
-		Image:       "mongo:latest",
-		Environment: `
-      MONGO_INITDB_DATABASE: ${GONE_DB_DATABASE}
-      MONGO_INITDB_ROOT_USERNAME: ${GONE_DB_USERNAME}
-      MONGO_INITDB_ROOT_PASSWORD: ${GONE_DB_PASSWORD}`,
+		Image:       "mongo:7",
+		Environment: map[string]string{
+			"MONGO_INITDB_DATABASE":      "${GONE_DB_DATABASE}",
+			"MONGO_INITDB_ROOT_USERNAME": "${GONE_DB_USERNAME}",
+			"MONGO_INITDB_ROOT_PASSWORD": "${GONE_DB_PASSWORD}",
+		},
+		Healthcheck: &compose.Healthcheck{
+			Test:     []string{"CMD", "mongosh", "--quiet", "--eval", "db.adminCommand('ping')"},
+			Interval: "5s",
+			Timeout:  "5s",
+			Retries:  10,
+		},
 		Port:       "27017",
 		Volume:     "mongo_volume_bp:/data/db",
 		VolumeName: "mongo_volume_bp",
 	},
 
 	"sqlite": {
-		ServiceName: "sqlite_bp",
-		Image:       "alpine:latest",
-		Environment: `
-      # SQLite has no environment variables`,
-		Port:        "0", // no port needed
+		// SQLite runs inside the application process, so it has no
+		// compose service of its own; its file lives in a volume on the app.
+		Port:        "0",
 		Volume:      "sqlite_volume_bp:/data",
 		VolumeName:  "sqlite_volume_bp",
 		DBName:      "SQLite",
-		DBEnvPrefix: "BLUEPRINT",
+		DBEnvPrefix: "GONE_DB",
+		Dialect:     "sqlite",
 		Import:      `_ "modernc.org/sqlite"`,
 		Driver:      "sqlite",
 		DSN:         "file:%s.db?_pragma=journal_mode(WAL)",
@@ -80,14 +174,20 @@ var DbRegistory = map[string]DbAddOneConfig{
 
 	"cockroachdb": {
 		ServiceName: "cockroach_bp",
-		Image:       "cockroachdb/cockroach:latest",
-		Environment: `
-      # CockroachDB has no env vars needed in insecure mode`,
+		Image:       "cockroachdb/cockroach:latest-v24.1",
+		Command:     []string{"start-single-node", "--insecure"},
+		Healthcheck: &compose.Healthcheck{
+			Test:     []string{"CMD", "curl", "-f", "http://localhost:8080/health?ready=1"},
+			Interval: "5s",
+			Timeout:  "5s",
+			Retries:  10,
+		},
 		Port:        "26257",
 		Volume:      "cockroach_volume_bp:/cockroach/cockroach-data",
 		VolumeName:  "cockroach_volume_bp",
 		DBName:      "CockroachDB",
-		DBEnvPrefix: "BLUEPRINT",
+		DBEnvPrefix: "GONE_DB",
+		Dialect:     "postgres",
 		Import:      `_ "github.com/jackc/pgx/v5/stdlib"`,
 		Driver:      "pgx",
 		DSN:         "postgres://%s:%s@%s:%s/%s?sslmode=disable",
@@ -95,17 +195,26 @@ var DbRegistory = map[string]DbAddOneConfig{
 
 	"mariadb": {
 		ServiceName: "mariadb_bp",
-		Image:       "mariadb:latest",
-		Environment: `
-      MARIADB_DATABASE: ${GONE_DB_DATABASE}
-      MARIADB_USER: ${GONE_DB_USERNAME}
-      MARIADB_PASSWORD: ${GONE_DB_PASSWORD}
-      MARIADB_ROOT_PASSWORD: ${GONE_DB_PASSWORD}`,
+		Image:       "mariadb:11",
+		Environment: map[string]string{
+			"MARIADB_DATABASE":      "${GONE_DB_DATABASE}",
+			"MARIADB_USER":          "${GONE_DB_USERNAME}",
+			"MARIADB_PASSWORD":      "${GONE_DB_PASSWORD}",
+			"MARIADB_ROOT_PASSWORD": "${GONE_DB_PASSWORD}",
+		},
+		Healthcheck: &compose.Healthcheck{
+			Test:        []string{"CMD", "healthcheck.sh", "--connect", "--innodb_initialized"},
+			Interval:    "5s",
+			Timeout:     "5s",
+			Retries:     10,
+			StartPeriod: "20s",
+		},
 		Port:        "3306",
 		Volume:      "mariadb_volume_bp:/var/lib/mysql",
 		VolumeName:  "mariadb_volume_bp",
 		DBName:      "MariaDB",
-		DBEnvPrefix: "BLUEPRINT",
+		DBEnvPrefix: "GONE_DB",
+		Dialect:     "mysql",
 		Import:      `_ "github.com/go-sql-driver/mysql"`,
 		Driver:      "mysql",
 		DSN:         "%s:%s@tcp(%s:%s)/%s",
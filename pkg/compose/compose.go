@@ -0,0 +1,225 @@
+// Package compose builds docker-compose documents out of service fragments
+// contributed by the app and its addons.
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conditions accepted by depends_on.
+const (
+	ServiceStarted = "service_started"
+	ServiceHealthy = "service_healthy"
+)
+
+type File struct {
+	Services map[string]Service  `yaml:"services"`
+	Volumes  map[string]struct{} `yaml:"volumes,omitempty"`
+}
+
+type Service struct {
+	Image       string                `yaml:"image,omitempty"`
+	Build       BuildSpec             `yaml:"build,omitempty"`
+	Command     []string              `yaml:"command,omitempty"`
+	Restart     string                `yaml:"restart,omitempty"`
+	EnvFile     []string              `yaml:"env_file,omitempty"`
+	Environment map[string]string     `yaml:"environment,omitempty"`
+	Ports       []string              `yaml:"ports,omitempty"`
+	Volumes     []string              `yaml:"volumes,omitempty"`
+	Healthcheck *Healthcheck          `yaml:"healthcheck,omitempty"`
+	DependsOn   map[string]Dependency `yaml:"depends_on,omitempty"`
+}
+
+// BuildSpec is how the image of a service is built. It is written as the
+// plain context directory unless a Dockerfile or build args are set.
+type BuildSpec struct {
+	Context    string            `yaml:"context"`
+	Dockerfile string            `yaml:"dockerfile,omitempty"`
+	Args       map[string]string `yaml:"args,omitempty"`
+}
+
+func (b *BuildSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		b.Context = node.Value
+		return nil
+	}
+
+	type plain BuildSpec
+	return node.Decode((*plain)(b))
+}
+
+func (b BuildSpec) MarshalYAML() (interface{}, error) {
+	if b.Dockerfile == "" && len(b.Args) == 0 {
+		return b.Context, nil
+	}
+
+	type plain BuildSpec
+	return plain(b), nil
+}
+
+func (b BuildSpec) IsZero() bool {
+	return b.Context == "" && b.Dockerfile == "" && len(b.Args) == 0
+}
+
+type Healthcheck struct {
+	Test        []string `yaml:"test"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	Retries     int      `yaml:"retries,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+}
+
+type Dependency struct {
+	Condition string `yaml:"condition"`
+}
+
+// Fragment is one service together with the named volumes it mounts.
+type Fragment struct {
+	Name    string
+	Service Service
+	Volumes []string
+}
+
+// Build merges the app fragment with its dependencies. The app waits for
+// every dependency, for healthy ones when they declare a healthcheck.
+func Build(app Fragment, deps ...Fragment) (*File, error) {
+	f := &File{Services: map[string]Service{}, Volumes: map[string]struct{}{}}
+
+	for _, dep := range deps {
+		if err := f.add(dep); err != nil {
+			return nil, err
+		}
+
+		condition := ServiceStarted
+		if dep.Service.Healthcheck != nil {
+			condition = ServiceHealthy
+		}
+		if app.Service.DependsOn == nil {
+			app.Service.DependsOn = map[string]Dependency{}
+		}
+		app.Service.DependsOn[dep.Name] = Dependency{Condition: condition}
+	}
+
+	if err := f.add(app); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Combine merges the files of several apps into one. A service defined in
+// more than one of them, such as a database the apps share, must be
+// defined alike in each and is kept once.
+func Combine(files ...*File) (*File, error) {
+	f := &File{Services: map[string]Service{}, Volumes: map[string]struct{}{}}
+	for _, file := range files {
+		for _, name := range sortedNames(file.Services) {
+			svc := file.Services[name]
+			if existing, ok := f.Services[name]; ok {
+				if !reflect.DeepEqual(existing, svc) {
+					return nil, fmt.Errorf("compose: service %q is defined differently by two apps", name)
+				}
+				continue
+			}
+			f.Services[name] = svc
+		}
+		for volume := range file.Volumes {
+			f.Volumes[volume] = struct{}{}
+		}
+	}
+	return f, nil
+}
+
+func (f *File) add(fragment Fragment) error {
+	if fragment.Name == "" {
+		return fmt.Errorf("compose: fragment without a service name")
+	}
+	if _, exists := f.Services[fragment.Name]; exists {
+		return fmt.Errorf("compose: service %q is defined twice", fragment.Name)
+	}
+
+	f.Services[fragment.Name] = fragment.Service
+	for _, volume := range fragment.Volumes {
+		f.Volumes[volume] = struct{}{}
+	}
+	return nil
+}
+
+// Marshal renders f and checks that the output parses back into a valid
+// document, so a broken compose file is never written.
+func (f *File) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := Validate(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Validate parses a compose document and checks the references between its
+// services and volumes.
+func Validate(data []byte) error {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("compose: invalid YAML: %w", err)
+	}
+	if len(f.Services) == 0 {
+		return fmt.Errorf("compose: no services defined")
+	}
+
+	for _, name := range sortedNames(f.Services) {
+		svc := f.Services[name]
+		if svc.Image == "" && svc.Build.IsZero() {
+			return fmt.Errorf("compose: service %q needs an image or a build context", name)
+		}
+		for dep, d := range svc.DependsOn {
+			target, ok := f.Services[dep]
+			if !ok {
+				return fmt.Errorf("compose: service %q depends on unknown service %q", name, dep)
+			}
+			switch d.Condition {
+			case ServiceStarted:
+			case ServiceHealthy:
+				if target.Healthcheck == nil {
+					return fmt.Errorf("compose: service %q waits for %q to be healthy but it has no healthcheck", name, dep)
+				}
+			default:
+				return fmt.Errorf("compose: service %q has unknown depends_on condition %q", name, d.Condition)
+			}
+		}
+		for _, mount := range svc.Volumes {
+			source, _, found := strings.Cut(mount, ":")
+			if !found || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") {
+				continue // bind mount
+			}
+			if _, ok := f.Volumes[source]; !ok {
+				return fmt.Errorf("compose: service %q mounts undeclared volume %q", name, source)
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortedNames(services map[string]Service) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
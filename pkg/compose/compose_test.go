@@ -0,0 +1,118 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuild_AppWaitsForDependencies(t *testing.T) {
+	db := Fragment{
+		Name: "db",
+		Service: Service{
+			Image:       "postgres:16",
+			Volumes:     []string{"db_data:/var/lib/postgresql/data"},
+			Healthcheck: &Healthcheck{Test: []string{"CMD", "pg_isready"}},
+		},
+		Volumes: []string{"db_data"},
+	}
+	cache := Fragment{Name: "cache", Service: Service{Image: "redis:7-alpine"}}
+
+	file, err := Build(Fragment{Name: "app", Service: Service{Build: BuildSpec{Context: "."}}}, db, cache)
+	require.NoError(t, err)
+
+	content, err := file.Marshal()
+	require.NoError(t, err)
+
+	var parsed File
+	require.NoError(t, yaml.Unmarshal(content, &parsed))
+	assert.Equal(t, map[string]Dependency{
+		"db":    {Condition: ServiceHealthy},
+		"cache": {Condition: ServiceStarted},
+	}, parsed.Services["app"].DependsOn)
+	assert.Contains(t, parsed.Volumes, "db_data")
+}
+
+func TestBuild_DuplicateService(t *testing.T) {
+	_, err := Build(Fragment{Name: "app", Service: Service{Build: BuildSpec{Context: "."}}},
+		Fragment{Name: "app", Service: Service{Image: "redis"}})
+	assert.EqualError(t, err, `compose: service "app" is defined twice`)
+}
+
+func TestCombine(t *testing.T) {
+	db := Fragment{Name: "db", Service: Service{Image: "postgres:16"}}
+	users, err := Build(Fragment{Name: "users", Service: Service{Build: BuildSpec{Context: "services/users"}}}, db)
+	require.NoError(t, err)
+	orders, err := Build(Fragment{Name: "orders", Service: Service{Build: BuildSpec{Context: "services/orders"}}}, db)
+	require.NoError(t, err)
+
+	file, err := Combine(users, orders)
+	require.NoError(t, err)
+	assert.Len(t, file.Services, 3)
+
+	other, err := Build(Fragment{Name: "billing", Service: Service{Build: BuildSpec{Context: "."}}},
+		Fragment{Name: "db", Service: Service{Image: "postgres:15"}})
+	require.NoError(t, err)
+	_, err = Combine(users, other)
+	assert.EqualError(t, err, `compose: service "db" is defined differently by two apps`)
+}
+
+func TestBuildSpec_Marshal(t *testing.T) {
+	out, err := yaml.Marshal(map[string]BuildSpec{
+		"plain": {Context: "."},
+		"args":  {Context: ".", Dockerfile: "Dockerfile", Args: map[string]string{"SERVICE": "users"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "args:\n    context: .\n    dockerfile: Dockerfile\n    args:\n        SERVICE: users\nplain: .\n", string(out))
+
+	var parsed map[string]BuildSpec
+	require.NoError(t, yaml.Unmarshal(out, &parsed))
+	assert.Equal(t, "users", parsed["args"].Args["SERVICE"])
+	assert.Equal(t, ".", parsed["plain"].Context)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			doc:  "services:\n  app:\n    build: .\n    volumes: [\"./data:/data\"]\n",
+		},
+		{
+			name:    "unknown dependency",
+			doc:     "services:\n  app:\n    build: .\n    depends_on:\n      db:\n        condition: service_started\n",
+			wantErr: `compose: service "app" depends on unknown service "db"`,
+		},
+		{
+			name:    "healthy without healthcheck",
+			doc:     "services:\n  db:\n    image: postgres\n  app:\n    build: .\n    depends_on:\n      db:\n        condition: service_healthy\n",
+			wantErr: `compose: service "app" waits for "db" to be healthy but it has no healthcheck`,
+		},
+		{
+			name:    "undeclared volume",
+			doc:     "services:\n  db:\n    image: postgres\n    volumes: [\"pg:/data\"]\n",
+			wantErr: `compose: service "db" mounts undeclared volume "pg"`,
+		},
+		{
+			name:    "not yaml",
+			doc:     "services: [",
+			wantErr: "compose: invalid YAML: yaml: line 1: did not find expected node content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.doc))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
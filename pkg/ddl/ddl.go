@@ -0,0 +1,559 @@
+// Package ddl reads CREATE TABLE statements from a SQL schema dump so that
+// existing databases can be described as bootstrap entities.
+package ddl
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+var Dialects = []Dialect{Postgres, MySQL, SQLite}
+
+func ParseDialect(name string) (Dialect, error) {
+	for _, d := range Dialects {
+		if strings.EqualFold(name, string(d)) {
+			return d, nil
+		}
+	}
+	return "", fmt.Errorf("unknown dialect %q (expected postgres, mysql or sqlite)", name)
+}
+
+type Table struct {
+	Name        string
+	Line        int
+	Columns     []Column
+	PrimaryKey  []string
+	ForeignKeys []ForeignKey
+}
+
+type Column struct {
+	Name          string
+	Type          string // raw SQL type, lower-cased, e.g. "varchar(255)"
+	Line          int
+	NotNull       bool
+	Default       string
+	HasDefault    bool
+	PrimaryKey    bool
+	Unique        bool
+	Index         bool
+	AutoIncrement bool
+}
+
+type ForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	Line       int
+}
+
+// Issue is a construct that was skipped or only partially understood.
+type Issue struct {
+	Line    int
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// Parse extracts the tables declared in src. Statements other than CREATE
+// TABLE, and clauses inside a table that have no entity equivalent, are
+// reported as issues rather than failing the whole file.
+func Parse(src string, dialect Dialect) ([]Table, []Issue, error) {
+	toks, err := lex(src, dialect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &schemaParser{}
+	for _, stmt := range splitTop(toks, ";") {
+		if len(stmt) == 0 {
+			continue
+		}
+		if err := p.statement(stmt); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return p.tables, p.issues, nil
+}
+
+type schemaParser struct {
+	tables []Table
+	issues []Issue
+}
+
+func (p *schemaParser) warn(line int, format string, args ...any) {
+	p.issues = append(p.issues, Issue{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *schemaParser) statement(stmt []token) error {
+	i := 0
+	if !stmt[i].is("create") {
+		p.warn(stmt[0].line, "unsupported statement %s, skipped", describe(stmt))
+		return nil
+	}
+	i++
+	for i < len(stmt) && (stmt[i].is("temporary") || stmt[i].is("temp") || stmt[i].is("unlogged")) {
+		i++
+	}
+	if i >= len(stmt) || !stmt[i].is("table") {
+		p.warn(stmt[0].line, "unsupported statement %s, skipped", describe(stmt))
+		return nil
+	}
+	i++
+	if i+2 < len(stmt) && stmt[i].is("if") && stmt[i+1].is("not") && stmt[i+2].is("exists") {
+		i += 3
+	}
+
+	name, next, err := qualifiedName(stmt, i)
+	if err != nil {
+		return err
+	}
+	i = next
+
+	if i >= len(stmt) || !stmt[i].isPunct("(") {
+		p.warn(stmt[0].line, "CREATE TABLE %s without a column list (e.g. AS SELECT) is not supported, skipped", name)
+		return nil
+	}
+	// Trailing storage options such as ENGINE=InnoDB or WITHOUT ROWID do not
+	// change the entity shape and are ignored.
+	body, _, err := parenthesized(stmt, i)
+	if err != nil {
+		return err
+	}
+
+	table := Table{Name: name, Line: stmt[0].line}
+	for _, def := range splitTop(body, ",") {
+		if len(def) == 0 {
+			continue
+		}
+		p.definition(&table, def)
+	}
+
+	// A composite primary key constrains no column on its own; its columns
+	// are only required.
+	for _, pk := range table.PrimaryKey {
+		for ci := range table.Columns {
+			if strings.EqualFold(table.Columns[ci].Name, pk) {
+				table.Columns[ci].PrimaryKey = len(table.PrimaryKey) == 1
+				table.Columns[ci].NotNull = true
+			}
+		}
+	}
+
+	p.tables = append(p.tables, table)
+	return nil
+}
+
+// definition handles one comma-separated item of a CREATE TABLE body.
+func (p *schemaParser) definition(t *Table, def []token) {
+	i := 0
+	line := def[0].line
+	if def[0].is("constraint") {
+		i = 2 // CONSTRAINT <name>
+		if i >= len(def) {
+			p.warn(line, "incomplete constraint in table %s, skipped", t.Name)
+			return
+		}
+	}
+
+	switch {
+	case def[i].is("primary"):
+		cols, ok := columnList(def, i+2)
+		if !ok {
+			p.warn(line, "could not read PRIMARY KEY columns of table %s", t.Name)
+			return
+		}
+		if len(cols) > 1 {
+			p.warn(line, "composite primary key (%s) in table %s is not supported, columns kept as required fields", strings.Join(cols, ", "), t.Name)
+		}
+		t.PrimaryKey = cols
+
+	case def[i].is("foreign"):
+		cols, ok := columnList(def, i+2)
+		if !ok {
+			p.warn(line, "could not read FOREIGN KEY columns of table %s", t.Name)
+			return
+		}
+		j := indexOf(def, "references")
+		if j < 0 {
+			p.warn(line, "FOREIGN KEY without REFERENCES in table %s", t.Name)
+			return
+		}
+		fk, ok := p.references(def, j, cols)
+		if ok {
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+
+	case def[i].is("unique"), def[i].is("key"), def[i].is("index"), def[i].is("fulltext"), def[i].is("spatial"):
+		unique := def[i].is("unique")
+		j := i + 1
+		for j < len(def) && !def[j].isPunct("(") {
+			j++
+		}
+		cols, ok := columnList(def, j)
+		if !ok {
+			p.warn(line, "could not read index columns of table %s", t.Name)
+			return
+		}
+		if len(cols) != 1 {
+			p.warn(line, "composite index on (%s) in table %s is not supported, skipped", strings.Join(cols, ", "), t.Name)
+			return
+		}
+		for ci := range t.Columns {
+			if strings.EqualFold(t.Columns[ci].Name, cols[0]) {
+				if unique {
+					t.Columns[ci].Unique = true
+				} else {
+					t.Columns[ci].Index = true
+				}
+			}
+		}
+
+	case def[i].is("check"), def[i].is("exclude"):
+		p.warn(line, "%s constraint in table %s is not supported, skipped", strings.ToUpper(def[i].text), t.Name)
+
+	default:
+		p.column(t, def)
+	}
+}
+
+// columnKeywords end a column's type and default expression.
+var columnKeywords = map[string]bool{
+	"not": true, "null": true, "default": true, "primary": true, "unique": true,
+	"references": true, "check": true, "constraint": true, "auto_increment": true,
+	"autoincrement": true, "collate": true, "generated": true, "comment": true,
+	"on": true, "identity": true, "key": true,
+}
+
+func isColumnKeyword(t token) bool {
+	return t.kind == tokWord && columnKeywords[strings.ToLower(t.text)]
+}
+
+func (p *schemaParser) column(t *Table, def []token) {
+	col := Column{Name: def[0].text, Line: def[0].line}
+	if def[0].kind != tokWord && def[0].kind != tokIdent {
+		p.warn(col.Line, "unexpected %q in table %s, skipped", def[0].text, t.Name)
+		return
+	}
+
+	// Type: words and parenthesised arguments up to the first constraint keyword.
+	i := 1
+	var typ []string
+	for i < len(def) && !isColumnKeyword(def[i]) {
+		if def[i].is("character") && i+1 < len(def) && def[i+1].is("set") {
+			break
+		}
+		if def[i].isPunct("(") {
+			args, rest, err := parenthesized(def, i)
+			if err != nil {
+				p.warn(col.Line, "%v", err)
+				return
+			}
+			typ = append(typ, "("+joinTokens(args)+")")
+			i = len(def) - len(rest)
+			continue
+		}
+		typ = append(typ, strings.ToLower(def[i].text))
+		i++
+	}
+	col.Type = strings.Join(typ, " ")
+	col.Type = strings.ReplaceAll(col.Type, " (", "(")
+	col.Type = strings.ReplaceAll(col.Type, " [ ]", "[]")
+	if col.Type == "" {
+		p.warn(col.Line, "column %s.%s has no type", t.Name, col.Name)
+	}
+
+	for i < len(def) {
+		tok := def[i]
+		switch {
+		case tok.is("not") && i+1 < len(def) && def[i+1].is("null"):
+			col.NotNull = true
+			i += 2
+		case tok.is("null"):
+			i++
+		case tok.is("default"):
+			value, next := defaultValue(def, i+1)
+			switch {
+			case strings.EqualFold(value, "null"):
+				// Same as having no default at all.
+			case strings.HasPrefix(strings.ToLower(value), "nextval("):
+				col.AutoIncrement = true
+			default:
+				col.Default, col.HasDefault = value, true
+			}
+			i = next
+		case tok.is("primary"):
+			col.PrimaryKey, col.NotNull = true, true
+			i += 2 // PRIMARY KEY
+			if i < len(def) && (def[i].is("asc") || def[i].is("desc")) {
+				i++
+			}
+		case tok.is("unique"):
+			col.Unique = true
+			i++
+			if i < len(def) && def[i].is("key") {
+				i++
+			}
+		case tok.is("auto_increment"), tok.is("autoincrement"):
+			col.AutoIncrement = true
+			i++
+		case tok.is("references"):
+			fk, ok := p.references(def, i, []string{col.Name})
+			if ok {
+				t.ForeignKeys = append(t.ForeignKeys, fk)
+			}
+			i = skipReferences(def, i)
+		case tok.is("constraint"):
+			i += 2
+		case tok.is("collate"):
+			i += 2
+		case tok.is("character") && i+1 < len(def) && def[i+1].is("set"):
+			i += 3
+		case tok.is("comment"):
+			i += 2
+		case tok.is("on") && i+1 < len(def) && def[i+1].is("update"):
+			// MySQL "ON UPDATE CURRENT_TIMESTAMP" is maintained by the ORM instead.
+			_, next := defaultValue(def, i+2)
+			i = next
+		case tok.is("generated"):
+			if rest := joinTokens(def[i:]); strings.Contains(strings.ToLower(rest), "identity") {
+				col.AutoIncrement = true
+			} else {
+				p.warn(tok.line, "generated column %s.%s is not supported, kept as a plain field", t.Name, col.Name)
+			}
+			i = len(def)
+		case tok.is("check"):
+			p.warn(tok.line, "CHECK constraint on %s.%s is not supported, skipped", t.Name, col.Name)
+			_, rest, err := parenthesized(def, i+1)
+			if err != nil {
+				i = len(def)
+				continue
+			}
+			i = len(def) - len(rest)
+		default:
+			p.warn(tok.line, "unsupported column option %q on %s.%s, skipped", tok.text, t.Name, col.Name)
+			i++
+		}
+	}
+
+	t.Columns = append(t.Columns, col)
+}
+
+func (p *schemaParser) references(def []token, i int, cols []string) (ForeignKey, bool) {
+	line := def[i].line
+	table, next, err := qualifiedName(def, i+1)
+	if err != nil {
+		p.warn(line, "could not read REFERENCES target: %v", err)
+		return ForeignKey{}, false
+	}
+	fk := ForeignKey{Columns: cols, RefTable: table, Line: line}
+	if next < len(def) && def[next].isPunct("(") {
+		if refCols, ok := columnList(def, next); ok {
+			fk.RefColumns = refCols
+		}
+	}
+	if len(fk.RefColumns) > 0 && len(fk.RefColumns) != len(fk.Columns) {
+		p.warn(line, "foreign key column count does not match referenced columns, skipped")
+		return ForeignKey{}, false
+	}
+	return fk, true
+}
+
+// skipReferences returns the index after "REFERENCES t (c) [ON DELETE ...]".
+func skipReferences(def []token, i int) int {
+	i++
+	_, i, _ = qualifiedName(def, i)
+	if i < len(def) && def[i].isPunct("(") {
+		if _, rest, err := parenthesized(def, i); err == nil {
+			i = len(def) - len(rest)
+		}
+	}
+	for i < len(def) {
+		switch {
+		case def[i].is("on") && i+1 < len(def) && (def[i+1].is("delete") || def[i+1].is("update")):
+			i += 2
+			if i < len(def) && (def[i].is("set") || def[i].is("no")) {
+				i++
+			}
+			i++
+		case def[i].is("match"), def[i].is("deferrable"), def[i].is("initially"):
+			i += 2
+		case def[i].is("not") && i+1 < len(def) && def[i+1].is("deferrable"):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// defaultValue reads a DEFAULT expression: a literal or a function call, with
+// an optional ::cast that is dropped. String literals are returned unquoted.
+func defaultValue(def []token, i int) (string, int) {
+	if i >= len(def) {
+		return "", i
+	}
+
+	var value string
+	switch tok := def[i]; {
+	case tok.isPunct("("):
+		args, rest, err := parenthesized(def, i)
+		if err != nil {
+			return "", len(def)
+		}
+		inner, _ := defaultValue(args, 0)
+		return inner, len(def) - len(rest)
+	case tok.isPunct("-") && i+1 < len(def):
+		value = "-" + def[i+1].text
+		i += 2
+	case tok.kind == tokString:
+		value = tok.text
+		i++
+	default:
+		value = tok.text
+		i++
+		if i < len(def) && def[i].isPunct("(") {
+			args, rest, err := parenthesized(def, i)
+			if err != nil {
+				return value, len(def)
+			}
+			value += "(" + joinTokens(args) + ")"
+			i = len(def) - len(rest)
+		}
+	}
+
+	if i < len(def) && def[i].isPunct("::") {
+		i++
+		for i < len(def) && !isColumnKeyword(def[i]) {
+			i++
+		}
+	}
+
+	return value, i
+}
+
+// qualifiedName reads "name" or "schema.name" and returns the last part.
+func qualifiedName(toks []token, i int) (string, int, error) {
+	if i >= len(toks) || (toks[i].kind != tokWord && toks[i].kind != tokIdent) {
+		return "", i, fmt.Errorf("line %d: expected a name", lineOf(toks, i))
+	}
+	name := toks[i].text
+	i++
+	for i+1 < len(toks) && toks[i].isPunct(".") {
+		name = toks[i+1].text
+		i += 2
+	}
+	return name, i, nil
+}
+
+// parenthesized returns the tokens between toks[i] == "(" and its matching
+// ")", and the tokens after it.
+func parenthesized(toks []token, i int) ([]token, []token, error) {
+	depth := 0
+	for j := i; j < len(toks); j++ {
+		switch {
+		case toks[j].isPunct("("):
+			depth++
+		case toks[j].isPunct(")"):
+			depth--
+			if depth == 0 {
+				return toks[i+1 : j], toks[j+1:], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("line %d: unbalanced parentheses", lineOf(toks, i))
+}
+
+func columnList(toks []token, i int) ([]string, bool) {
+	if i >= len(toks) || !toks[i].isPunct("(") {
+		return nil, false
+	}
+	inner, _, err := parenthesized(toks, i)
+	if err != nil {
+		return nil, false
+	}
+	var cols []string
+	for _, part := range splitTop(inner, ",") {
+		if len(part) == 0 {
+			return nil, false
+		}
+		cols = append(cols, part[0].text)
+	}
+	return cols, len(cols) > 0
+}
+
+// splitTop splits toks on the punctuation sep at parenthesis depth zero.
+func splitTop(toks []token, sep string) [][]token {
+	var parts [][]token
+	depth, start := 0, 0
+	for j, tok := range toks {
+		switch {
+		case tok.isPunct("("):
+			depth++
+		case tok.isPunct(")"):
+			depth--
+		case tok.isPunct(sep) && depth == 0:
+			parts = append(parts, toks[start:j])
+			start = j + 1
+		}
+	}
+	return append(parts, toks[start:])
+}
+
+func indexOf(toks []token, kw string) int {
+	for j, tok := range toks {
+		if tok.is(kw) {
+			return j
+		}
+	}
+	return -1
+}
+
+func joinTokens(toks []token) string {
+	var b strings.Builder
+	for j, tok := range toks {
+		if j > 0 && !tok.isPunct(",") && !tok.isPunct(")") && !toks[j-1].isPunct("(") {
+			b.WriteByte(' ')
+		}
+		if tok.kind == tokString {
+			b.WriteString("'" + tok.text + "'")
+			continue
+		}
+		b.WriteString(tok.text)
+	}
+	return b.String()
+}
+
+func describe(stmt []token) string {
+	words := make([]string, 0, 2)
+	for _, tok := range stmt {
+		if tok.kind != tokWord || len(words) == 2 {
+			break
+		}
+		words = append(words, strings.ToUpper(tok.text))
+	}
+	if len(words) == 0 {
+		return fmt.Sprintf("%q", stmt[0].text)
+	}
+	return strings.Join(words, " ")
+}
+
+func lineOf(toks []token, i int) int {
+	if i < len(toks) {
+		return toks[i].line
+	}
+	if len(toks) > 0 {
+		return toks[len(toks)-1].line
+	}
+	return 0
+}
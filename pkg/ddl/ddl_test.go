@@ -0,0 +1,182 @@
+package ddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+const postgresSchema = `-- shop schema
+CREATE TABLE users (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    status TEXT DEFAULT 'active'::text,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX users_status_idx ON users (status);
+
+CREATE TABLE IF NOT EXISTS public.order_items (
+    id SERIAL,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    price NUMERIC(10, 2),
+    tags TEXT[],
+    CONSTRAINT order_items_pkey PRIMARY KEY (id),
+    CHECK (price > 0)
+);
+`
+
+func TestParse_Postgres(t *testing.T) {
+	tables, issues, err := Parse(postgresSchema, Postgres)
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+
+	users := tables[0]
+	assert.Equal(t, "users", users.Name)
+	assert.Equal(t, 2, users.Line)
+	require.Len(t, users.Columns, 4)
+	assert.True(t, users.Columns[0].PrimaryKey)
+	assert.Equal(t, "varchar(255)", users.Columns[1].Type)
+	assert.True(t, users.Columns[1].NotNull)
+	assert.True(t, users.Columns[1].Unique)
+	assert.Equal(t, "active", users.Columns[2].Default)
+
+	items := tables[1]
+	assert.Equal(t, "order_items", items.Name)
+	assert.Equal(t, []string{"id"}, items.PrimaryKey)
+	require.Len(t, items.ForeignKeys, 1)
+	assert.Equal(t, "users", items.ForeignKeys[0].RefTable)
+
+	var lines []int
+	for _, issue := range issues {
+		lines = append(lines, issue.Line)
+	}
+	assert.Equal(t, []int{9, 17}, lines, "CREATE INDEX and CHECK should be reported")
+}
+
+func TestEntities_Postgres(t *testing.T) {
+	tables, _, err := Parse(postgresSchema, Postgres)
+	require.NoError(t, err)
+
+	entities, issues := Entities(tables, Postgres)
+	require.Len(t, entities, 2)
+
+	assert.Equal(t, "user", entities[0].Name)
+	assert.Equal(t, []parser.Field{
+		{Name: "email", Type: "string", Unique: true},
+		{Name: "status", Type: "text", Nullable: true, Default: "active"},
+	}, entities[0].Fields)
+
+	assert.Equal(t, "orderItem", entities[1].Name)
+	assert.Equal(t, []parser.Relation{
+		{Type: "belongs_to", Entity: "user", ForeignKey: "user_id", Required: true},
+	}, entities[1].Relations)
+	require.Len(t, entities[1].Fields, 2)
+	assert.Equal(t, "decimal", entities[1].Fields[0].Type)
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, 15, issues[0].Line, "array column should be reported")
+}
+
+func TestParse_MySQL(t *testing.T) {
+	schema := "CREATE TABLE `accounts` (\n" +
+		"  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `active` tinyint(1) NOT NULL DEFAULT '1',\n" +
+		"  `name` varchar(64) COLLATE utf8mb4_bin DEFAULT NULL COMMENT 'display name', # inline\n" +
+		"  `updated` datetime ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_name` (`name`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n"
+
+	tables, issues, err := Parse(schema, MySQL)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	entities, issues := Entities(tables, MySQL)
+	assert.Empty(t, issues)
+	require.Len(t, entities, 1)
+	assert.Equal(t, "account", entities[0].Name)
+	assert.Equal(t, []parser.Field{
+		{Name: "active", Type: "bool", Default: "1"},
+		{Name: "name", Type: "string", Nullable: true, Index: true},
+		{Name: "updated", Type: "time", Nullable: true},
+	}, entities[0].Fields)
+}
+
+func TestFieldType_SQLiteAffinity(t *testing.T) {
+	for sqlType, want := range map[string]string{
+		"INTEGER":               "int",
+		"UNSIGNED BIG":          "decimal",
+		"VARYING CHARACTER(20)": "text",
+		"NATIVE CHARACTER(70)":  "text",
+		"BLOB":                  "bytes",
+		"DOUBLE":                "float",
+	} {
+		got, ok := FieldType(sqlType, SQLite)
+		assert.True(t, ok, sqlType)
+		assert.Equal(t, want, got, sqlType)
+	}
+}
+
+func TestParse_UnterminatedComment(t *testing.T) {
+	_, _, err := Parse("CREATE TABLE a (id int);\n/* oops", Postgres)
+	assert.EqualError(t, err, "line 2: unterminated comment")
+}
+
+func TestEntities_ReplacesNonIntegerID(t *testing.T) {
+	tables, _, err := Parse("CREATE TABLE sessions (\n  id uuid PRIMARY KEY,\n  token text NOT NULL\n);\n", Postgres)
+	require.NoError(t, err)
+
+	entities, issues := Entities(tables, Postgres)
+	require.Len(t, entities, 1)
+	assert.Equal(t, []parser.Field{{Name: "token", Type: "text"}}, entities[0].Fields)
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "replaced by the generated integer id")
+}
+
+func TestEntities_InlineAndTableForeignKey(t *testing.T) {
+	schema := "CREATE TABLE customers (\n  id serial PRIMARY KEY\n);\n" +
+		"CREATE TABLE orders (\n" +
+		"  id serial PRIMARY KEY,\n" +
+		"  customer_id integer NOT NULL REFERENCES customers (id),\n" +
+		"  total numeric(10,2),\n" +
+		"  CONSTRAINT orders_customer_fk FOREIGN KEY (customer_id) REFERENCES customers (id)\n" +
+		");\n"
+	tables, _, err := Parse(schema, Postgres)
+	require.NoError(t, err)
+	require.Len(t, tables[1].ForeignKeys, 2, "both forms should be parsed")
+
+	entities, issues := Entities(tables, Postgres)
+	require.Len(t, entities, 2)
+	assert.Equal(t, []parser.Relation{
+		{Type: "belongs_to", Entity: "customer", ForeignKey: "customer_id", Required: true},
+	}, entities[1].Relations)
+	assert.Empty(t, issues)
+	assert.NoError(t, (&parser.Config{Entities: entities}).CheckRelations())
+}
+
+func TestEntities_CompositePrimaryKey(t *testing.T) {
+	schema := "CREATE TABLE countries (\n  code char(2) PRIMARY KEY\n);\n" +
+		"CREATE TABLE order_tags (\n" +
+		"  order_id integer,\n" +
+		"  tag_id integer,\n" +
+		"  PRIMARY KEY (order_id, tag_id)\n" +
+		");\n"
+	tables, parseIssues, err := Parse(schema, Postgres)
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{{Line: 7, Message: "composite primary key (order_id, tag_id) in table order_tags is not supported, columns kept as required fields"}}, parseIssues)
+
+	entities, issues := Entities(tables, Postgres)
+	assert.Empty(t, issues)
+	require.Len(t, entities, 2)
+	assert.Equal(t, []parser.Field{{Name: "code", Type: "string", Unique: true, PrimaryKey: true}}, entities[0].Fields,
+		"a single primary key stays unique")
+	assert.Equal(t, []parser.Field{
+		{Name: "order_id", Type: "int"},
+		{Name: "tag_id", Type: "int"},
+	}, entities[1].Fields, "the columns of a composite key are not unique on their own")
+}
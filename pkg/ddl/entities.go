@@ -0,0 +1,222 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+// implicitColumns are provided by every generated model, so they are not
+// repeated as entity fields.
+var implicitColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+// Entities converts parsed tables into project.yaml entities. Single-column
+// foreign keys become belongs_to relations; everything that cannot be
+// expressed is reported as an issue.
+func Entities(tables []Table, dialect Dialect) ([]parser.Entity, []Issue) {
+	var issues []Issue
+
+	entityOf := make(map[string]string, len(tables))
+	for _, t := range tables {
+		entityOf[strings.ToLower(t.Name)] = EntityName(t.Name)
+	}
+
+	entities := make([]parser.Entity, 0, len(tables))
+	for _, t := range tables {
+		entity := parser.Entity{Name: EntityName(t.Name)}
+
+		// fkColumns maps the foreign key columns to the entity they
+		// reference: a column can carry both an inline REFERENCES and a
+		// table-level FOREIGN KEY, which are one relation.
+		fkColumns := map[string]string{}
+		for _, fk := range t.ForeignKeys {
+			target, known := entityOf[strings.ToLower(fk.RefTable)]
+			switch {
+			case len(fk.Columns) != 1:
+				issues = append(issues, Issue{Line: fk.Line, Message: fmt.Sprintf(
+					"composite foreign key (%s) in table %s is not supported, columns kept as plain fields",
+					strings.Join(fk.Columns, ", "), t.Name)})
+				continue
+			case !known:
+				issues = append(issues, Issue{Line: fk.Line, Message: fmt.Sprintf(
+					"%s.%s references table %s which is not defined in this file, kept as a plain field",
+					t.Name, fk.Columns[0], fk.RefTable)})
+				continue
+			}
+
+			switch seen, dup := fkColumns[strings.ToLower(fk.Columns[0])]; {
+			case dup && seen == target:
+				continue
+			case dup:
+				issues = append(issues, Issue{Line: fk.Line, Message: fmt.Sprintf(
+					"%s.%s also references table %s, only its first foreign key is kept",
+					t.Name, fk.Columns[0], fk.RefTable)})
+				continue
+			}
+
+			col := findColumn(t, fk.Columns[0])
+			entity.Relations = append(entity.Relations, parser.Relation{
+				Type:       "belongs_to",
+				Entity:     target,
+				ForeignKey: fk.Columns[0],
+				Required:   col != nil && col.NotNull,
+			})
+			fkColumns[strings.ToLower(fk.Columns[0])] = target
+		}
+
+		for _, col := range t.Columns {
+			name := strings.ToLower(col.Name)
+			if _, fk := fkColumns[name]; fk || implicitColumns[name] {
+				continue
+			}
+			if name == "id" {
+				if !isImplicitID(t, col, dialect) {
+					issues = append(issues, Issue{Line: col.Line, Message: fmt.Sprintf(
+						"column %s.%s (%s) is replaced by the generated integer id", t.Name, col.Name, col.Type)})
+				}
+				continue
+			}
+
+			typ, ok := FieldType(col.Type, dialect)
+			if !ok {
+				issues = append(issues, Issue{Line: col.Line, Message: fmt.Sprintf(
+					"column %s.%s has unsupported type %q, imported as string", t.Name, col.Name, col.Type)})
+			}
+
+			entity.Fields = append(entity.Fields, parser.Field{
+				Name:       col.Name,
+				Type:       typ,
+				Nullable:   !col.NotNull && !col.PrimaryKey,
+				Default:    col.Default,
+				Unique:     col.Unique || col.PrimaryKey,
+				Index:      col.Index,
+				PrimaryKey: col.PrimaryKey,
+			})
+		}
+
+		entities = append(entities, entity)
+	}
+
+	return entities, issues
+}
+
+// EntityName turns a table name such as "order_items" into the entity name
+// "orderItem".
+func EntityName(table string) string {
+	words := naming.Words(table)
+	if len(words) == 0 {
+		return table
+	}
+	words[len(words)-1] = naming.Singular(words[len(words)-1])
+	return naming.Camel(strings.Join(words, "_"))
+}
+
+// isImplicitID reports whether col is the auto-incrementing integer "id"
+// primary key that every generated model already provides.
+func isImplicitID(t Table, col Column, dialect Dialect) bool {
+	if !strings.EqualFold(col.Name, "id") || !col.PrimaryKey || countPrimaryKey(t) != 1 {
+		return false
+	}
+	typ, _ := FieldType(col.Type, dialect)
+	return typ == "int" || typ == "int64"
+}
+
+func countPrimaryKey(t Table) int {
+	n := 0
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			n++
+		}
+	}
+	return n
+}
+
+func findColumn(t Table, name string) *Column {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+var sqlTypes = map[string]string{
+	"smallint": "int", "int2": "int", "integer": "int", "int": "int", "int4": "int",
+	"mediumint": "int", "tinyint": "int", "serial": "int", "smallserial": "int",
+	"bigint": "int64", "int8": "int64", "bigserial": "int64",
+
+	"real": "float", "float": "float", "float4": "float", "float8": "float",
+	"double": "float", "double precision": "float",
+	"numeric": "decimal", "decimal": "decimal", "money": "decimal",
+
+	"boolean": "bool", "bool": "bool",
+
+	"varchar": "string", "character varying": "string", "char": "string",
+	"character": "string", "nvarchar": "string", "nchar": "string",
+	"citext": "string", "enum": "string", "set": "string", "inet": "string",
+	"text": "text", "tinytext": "text", "mediumtext": "text", "longtext": "text", "clob": "text",
+
+	"date": "time", "datetime": "time", "timestamp": "time", "timestamptz": "time",
+	"timestamp with time zone": "time", "timestamp without time zone": "time",
+	"time": "time", "timetz": "time",
+
+	"uuid": "uuid", "json": "json", "jsonb": "json",
+
+	"bytea": "bytes", "blob": "bytes", "tinyblob": "bytes", "mediumblob": "bytes",
+	"longblob": "bytes", "binary": "bytes", "varbinary": "bytes",
+}
+
+// FieldType maps a SQL column type to a project.yaml field type. It returns
+// "string" and false when the type is not recognised.
+func FieldType(sqlType string, dialect Dialect) (string, bool) {
+	t := strings.ToLower(strings.TrimSpace(sqlType))
+	if strings.HasSuffix(t, "[]") {
+		return "string", false
+	}
+
+	base, args := t, ""
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		base, args = strings.TrimSpace(t[:i]), t[i:]
+		if j := strings.IndexByte(args, ')'); j >= 0 {
+			base = strings.TrimSpace(base + " " + strings.TrimSpace(args[j+1:]))
+			args = args[:j+1]
+		}
+	}
+	base = strings.TrimSuffix(base, " unsigned")
+	base = strings.TrimSuffix(base, " zerofill")
+
+	if dialect == MySQL && base == "tinyint" && args == "(1)" {
+		return "bool", true
+	}
+	if dialect == MySQL && base == "bit" && (args == "" || args == "(1)") {
+		return "bool", true
+	}
+
+	if typ, ok := sqlTypes[base]; ok {
+		return typ, true
+	}
+
+	// SQLite accepts any type name and applies affinity rules instead.
+	if dialect == SQLite {
+		switch {
+		case strings.Contains(base, "int"):
+			return "int64", true
+		case strings.Contains(base, "char"), strings.Contains(base, "clob"), strings.Contains(base, "text"):
+			return "text", true
+		case strings.Contains(base, "blob"), base == "":
+			return "bytes", true
+		case strings.Contains(base, "real"), strings.Contains(base, "floa"), strings.Contains(base, "doub"):
+			return "float", true
+		default:
+			return "decimal", true
+		}
+	}
+
+	return "string", false
+}
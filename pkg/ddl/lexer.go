@@ -0,0 +1,124 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// is reports whether t is the bare keyword kw (case-insensitive). Quoted
+// identifiers never match keywords.
+func (t token) is(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+func (t token) isPunct(p string) bool {
+	return t.kind == tokPunct && t.text == p
+}
+
+// lex splits src into tokens, dropping whitespace and comments. Identifiers
+// in double quotes, backticks or (SQLite) brackets become tokIdent without
+// their quotes.
+func lex(src string, dialect Dialect) ([]token, error) {
+	var toks []token
+	line := 1
+	r := []rune(src)
+
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-',
+			c == '#' && dialect == MySQL:
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			start := line
+			i += 2
+			for i < len(r) && !(r[i] == '*' && i+1 < len(r) && r[i+1] == '/') {
+				if r[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(r) {
+				return nil, fmt.Errorf("line %d: unterminated comment", start)
+			}
+			i += 2
+		case c == '\'' || c == '"' || c == '`' || (c == '[' && dialect == SQLite):
+			closing := c
+			kind := tokIdent
+			if c == '\'' {
+				kind = tokString
+			}
+			if c == '[' {
+				closing = ']'
+			}
+			start := line
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(r) {
+					return nil, fmt.Errorf("line %d: unterminated quoted text", start)
+				}
+				if r[i] == closing {
+					// A doubled quote is an escaped quote.
+					if closing != ']' && i+1 < len(r) && r[i+1] == closing {
+						b.WriteRune(closing)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if r[i] == '\n' {
+					line++
+				}
+				b.WriteRune(r[i])
+				i++
+			}
+			toks = append(toks, token{kind: kind, text: b.String(), line: start})
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(r) && (unicode.IsDigit(r[i]) || r[i] == '.') {
+				i++
+			}
+			toks = append(toks, token{kind: tokNumber, text: string(r[start:i]), line: line})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(r) && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) || r[i] == '_' || r[i] == '$') {
+				i++
+			}
+			toks = append(toks, token{kind: tokWord, text: string(r[start:i]), line: line})
+		case c == ':' && i+1 < len(r) && r[i+1] == ':':
+			toks = append(toks, token{kind: tokPunct, text: "::", line: line})
+			i += 2
+		default:
+			toks = append(toks, token{kind: tokPunct, text: string(c), line: line})
+			i++
+		}
+	}
+
+	return toks, nil
+}
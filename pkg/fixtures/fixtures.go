@@ -0,0 +1,288 @@
+// Package fixtures produces deterministic fake rows for entities, based on
+// the types of their fields and their validation rules.
+package fixtures
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+var firstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Ken", "Barbara", "Dennis", "Radia", "Rob"}
+var lastNames = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Thompson", "Liskov", "Ritchie", "Perlman", "Pike"}
+var words = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet"}
+
+// epoch anchors generated timestamps so that output does not depend on the
+// current time.
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generate returns count rows for entity. The same seed always yields the
+// same rows; ids run from 1 to count so that re-seeding is idempotent.
+//
+// Foreign keys of belongs_to relations are drawn from parents, the ids of
+// the rows of each target entity keyed by the entity named in the relation.
+// A target without rows leaves the foreign key null and adds a warning.
+// Relations of an entity to itself reference the rows generated before, so
+// the first row has no parent unless the relation is required.
+//
+// Values follow the validate rules of their field, so that the rows pass
+// the checks of the generated API. Rules that cannot be met are reported as
+// warnings: fields with a regex rule are left out of the rows unless the
+// value generated for them happens to match.
+func Generate(entity parser.Entity, count int, seed int64, parents map[string][]int) ([]map[string]any, []string) {
+	g := &generator{rng: rand.New(rand.NewSource(seed)), warned: map[string]bool{}}
+
+	rows := make([]map[string]any, 0, count)
+	for n := 1; n <= count; n++ {
+		row := map[string]any{"id": n}
+		for _, field := range entity.Fields {
+			if field.PrimaryKey && strings.EqualFold(field.Name, "id") {
+				continue
+			}
+			if v, ok := g.value(field, n); ok {
+				row[field.JSONName()] = v
+			}
+		}
+		for _, rel := range entity.Relations {
+			if rel.Type == parser.BelongsTo {
+				row[rel.ForeignKeyColumn(entity.Name)] = g.parent(entity, rel, parents, n)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, g.warnings
+}
+
+type generator struct {
+	rng      *rand.Rand
+	warnings []string
+	warned   map[string]bool
+}
+
+// warn records a warning about field.
+func (g *generator) warn(field parser.Field, format string, args ...any) {
+	g.note(fmt.Sprintf("field %q: ", field.Name) + fmt.Sprintf(format, args...))
+}
+
+// note records a warning once, however many rows run into it.
+func (g *generator) note(msg string) {
+	if !g.warned[msg] {
+		g.warned[msg] = true
+		g.warnings = append(g.warnings, msg)
+	}
+}
+
+// parent returns the foreign key of rel in row n of entity, or nil when
+// there is no row to reference.
+func (g *generator) parent(entity parser.Entity, rel parser.Relation, parents map[string][]int, n int) any {
+	if strings.EqualFold(rel.Entity, entity.Name) {
+		// A required relation lets the first row reference itself.
+		last := n - 1
+		if rel.Required {
+			last = n
+		}
+		if last == 0 {
+			return nil
+		}
+		return 1 + g.rng.Intn(last)
+	}
+
+	ids := parents[rel.Entity]
+	if len(ids) == 0 {
+		g.note(fmt.Sprintf("relation to %q: no rows to reference, foreign key %s left null", rel.Entity, rel.ForeignKeyColumn(entity.Name)))
+		return nil
+	}
+	return ids[g.rng.Intn(len(ids))]
+}
+
+// value returns the value of field in row n, or false when the field is
+// left out of the row.
+func (g *generator) value(field parser.Field, n int) (any, bool) {
+	rules := field.Validate
+	if rules == nil {
+		rules = &parser.Rules{}
+	}
+
+	v, ok := g.ruled(field, rules, n)
+	if !ok {
+		return nil, false
+	}
+	if rules.Regex != "" {
+		s, isString := v.(string)
+		if re, err := regexp.Compile(rules.Regex); err != nil || !isString || !re.MatchString(s) {
+			g.warn(field, "values matching regex %q cannot be generated, left out of the rows", rules.Regex)
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func (g *generator) ruled(field parser.Field, rules *parser.Rules, n int) (any, bool) {
+	rng := g.rng
+
+	if len(rules.OneOf) > 0 {
+		choice := rules.OneOf[rng.Intn(len(rules.OneOf))]
+		if field.Unique {
+			if n > len(rules.OneOf) {
+				g.warn(field, "unique with %d oneof values, left out of the rows after the first %d", len(rules.OneOf), len(rules.OneOf))
+				return nil, false
+			}
+			choice = rules.OneOf[n-1]
+		}
+		if field.Type == "int" || field.Type == "int64" {
+			i, err := strconv.ParseInt(choice, 10, 64)
+			if err != nil {
+				g.warn(field, "oneof value %q is not an integer, left out of the rows", choice)
+				return nil, false
+			}
+			return i, true
+		}
+		return choice, true
+	}
+
+	bounded := rules.Min != nil || rules.Max != nil
+	switch field.Type {
+	case "int", "int64":
+		def := 999.0
+		if field.Type == "int64" {
+			def = 999_999
+		}
+		if !bounded {
+			if field.Unique {
+				return n, true
+			}
+			if field.Type == "int" {
+				return rng.Intn(1000), true
+			}
+			return rng.Int63n(1_000_000), true
+		}
+		lo, hi := bounds(rules, def)
+		from, to := int64(math.Ceil(lo)), int64(math.Floor(hi))
+		if from > to {
+			g.warn(field, "no integer lies between min %v and max %v, left out of the rows", lo, hi)
+			return nil, false
+		}
+		if field.Unique {
+			if v := from + int64(n) - 1; v <= to {
+				return v, true
+			}
+			g.warn(field, "unique with only %d values between min and max, left out of the rows after them", to-from+1)
+			return nil, false
+		}
+		return from + rng.Int63n(to-from+1), true
+	case "float", "decimal":
+		if !bounded {
+			return math.Round(rng.Float64()*100_000) / 100, true
+		}
+		lo, hi := bounds(rules, 1000)
+		return math.Min(math.Max(math.Round((lo+rng.Float64()*(hi-lo))*100)/100, lo), hi), true
+	case "bool":
+		return rng.Intn(2) == 1, true
+	case "time":
+		return epoch.Add(time.Duration(rng.Intn(365*24)) * time.Hour).Format(time.RFC3339), true
+	case "uuid":
+		b := make([]byte, 16)
+		rng.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), true
+	case "json":
+		doc := map[string]any{"seq": n}
+		raw, _ := json.Marshal(doc)
+		if rules.Min != nil && float64(len(raw)) < *rules.Min {
+			// {"note":"","seq":n} adds 10 bytes before the padding.
+			doc["note"] = strings.Repeat("x", max(0, int(math.Ceil(*rules.Min))-len(raw)-10))
+			raw, _ = json.Marshal(doc)
+		}
+		if rules.Max != nil && float64(len(raw)) > *rules.Max {
+			g.warn(field, "no JSON document of at most %v bytes can be generated, left out of the rows", *rules.Max)
+			return nil, false
+		}
+		return doc, true
+	case "bytes":
+		size := 8
+		if rules.Min != nil && float64(size) < *rules.Min {
+			size = int(math.Ceil(*rules.Min))
+		}
+		if rules.Max != nil && float64(size) > *rules.Max {
+			size = int(math.Floor(*rules.Max))
+		}
+		b := make([]byte, size)
+		rng.Read(b)
+		return base64.StdEncoding.EncodeToString(b), true
+	}
+
+	// Strings are built as a head and a tail: fitting them to min and max
+	// pads or cuts the head, so that the tail, which keeps unique values
+	// unique and emails valid, survives.
+	var head, tail string
+	name := strings.ToLower(field.Name)
+	if field.Type == "text" && !rules.Email {
+		parts := make([]string, 8)
+		for i := range parts {
+			parts[i] = words[rng.Intn(len(words))]
+		}
+		head = strings.Join(parts, " ")
+	} else {
+		first := firstNames[rng.Intn(len(firstNames))]
+		last := lastNames[rng.Intn(len(lastNames))]
+		switch {
+		case rules.Email || strings.Contains(name, "email"):
+			head, tail = strings.ToLower(first)+"."+strings.ToLower(last), fmt.Sprintf("%d@example.com", n)
+		case strings.HasSuffix(name, "name"):
+			head = first + " " + last
+			if field.Unique {
+				tail = fmt.Sprintf(" %d", n)
+			}
+		default:
+			head = words[rng.Intn(len(words))]
+			if field.Unique {
+				tail = fmt.Sprintf("-%d", n)
+			}
+		}
+	}
+
+	length := utf8.RuneCountInString(head) + utf8.RuneCountInString(tail)
+	if rules.Min != nil && float64(length) < *rules.Min {
+		head += strings.Repeat("x", int(math.Ceil(*rules.Min))-length)
+	}
+	if rules.Max != nil && float64(length) > *rules.Max {
+		keep := int(math.Floor(*rules.Max)) - utf8.RuneCountInString(tail)
+		if keep < 1 {
+			g.warn(field, "no value of at most %v characters can be generated, left out of the rows", *rules.Max)
+			return nil, false
+		}
+		head = strings.TrimRight(string([]rune(head)[:keep]), " .")
+		if head == "" {
+			head = "x"
+		}
+	}
+	return head + tail, true
+}
+
+// bounds returns the range of a numeric field: its min and max, with def
+// standing in for the width of the range when one of them is missing, and
+// [0, def] when both are.
+func bounds(rules *parser.Rules, def float64) (lo, hi float64) {
+	switch {
+	case rules.Min != nil && rules.Max != nil:
+		return *rules.Min, *rules.Max
+	case rules.Min != nil:
+		return *rules.Min, *rules.Min + def
+	case rules.Max != nil && *rules.Max < 0:
+		return *rules.Max - def, *rules.Max
+	case rules.Max != nil:
+		return 0, *rules.Max
+	}
+	return 0, def
+}
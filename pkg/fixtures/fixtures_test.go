@@ -0,0 +1,206 @@
+package fixtures
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+var order = parser.Entity{
+	Name: "order",
+	Fields: []parser.Field{
+		{Name: "id", Type: "int", PrimaryKey: true},
+		{Name: "email", Type: "string", Unique: true},
+		{Name: "full_name", Type: "string"},
+		{Name: "notes", Type: "text"},
+		{Name: "qty", Type: "int"},
+		{Name: "total", Type: "int64"},
+		{Name: "price", Type: "decimal"},
+		{Name: "paid", Type: "bool"},
+		{Name: "placed_at", Type: "time"},
+		{Name: "ref", Type: "uuid"},
+		{Name: "meta", Type: "json"},
+		{Name: "receipt", Type: "bytes"},
+	},
+	Relations: []parser.Relation{{Type: parser.BelongsTo, Entity: "customer"}},
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	parents := map[string][]int{"customer": {1, 2, 3}}
+
+	first, _ := Generate(order, 20, 42, parents)
+	second, _ := Generate(order, 20, 42, parents)
+	assert.Equal(t, first, second, "the same seed yields the same rows")
+
+	other, _ := Generate(order, 20, 43, parents)
+	assert.NotEqual(t, first, other, "another seed yields other rows")
+}
+
+func TestGenerate_Types(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	rows, warnings := Generate(order, 10, 1, map[string][]int{"customer": {1}})
+	require.Len(t, rows, 10)
+	assert.Empty(t, warnings)
+
+	emails := map[any]bool{}
+	for i, row := range rows {
+		assert.Equal(t, i+1, row["id"])
+		assert.Len(t, row, 13, "every field and foreign key is set")
+
+		assert.Contains(t, row["email"], "@example.com")
+		assert.False(t, emails[row["email"]], "unique values do not repeat")
+		emails[row["email"]] = true
+		assert.IsType(t, "", row["full_name"])
+		assert.IsType(t, "", row["notes"])
+		assert.IsType(t, 0, row["qty"])
+		assert.IsType(t, int64(0), row["total"])
+		assert.IsType(t, float64(0), row["price"])
+		assert.IsType(t, false, row["paid"])
+		assert.IsType(t, map[string]any{}, row["meta"])
+
+		_, err := time.Parse(time.RFC3339, row["placed_at"].(string))
+		assert.NoError(t, err)
+		assert.Regexp(t, uuid, row["ref"])
+		_, err = base64.StdEncoding.DecodeString(row["receipt"].(string))
+		assert.NoError(t, err)
+	}
+}
+
+func TestGenerate_ForeignKeys(t *testing.T) {
+	entity := parser.Entity{
+		Name: "order",
+		Relations: []parser.Relation{
+			{Type: parser.BelongsTo, Entity: "customer", Required: true},
+			{Type: parser.BelongsTo, Entity: "warehouse"},
+			{Type: parser.BelongsTo, Entity: "order", Name: "parent", ForeignKey: "parent_id"},
+			{Type: parser.HasMany, Entity: "item"},
+		},
+	}
+	customers := []int{7, 8, 42}
+
+	rows, warnings := Generate(entity, 50, 1, map[string][]int{"customer": customers})
+	assert.Equal(t, []string{`relation to "warehouse": no rows to reference, foreign key warehouse_id left null`}, warnings)
+
+	seen := map[any]bool{}
+	for i, row := range rows {
+		assert.Contains(t, customers, row["customer_id"], "foreign keys reference parent rows")
+		seen[row["customer_id"]] = true
+		assert.Nil(t, row["warehouse_id"])
+		assert.NotContains(t, row, "item_id")
+
+		if i == 0 {
+			assert.Nil(t, row["parent_id"], "the first row has no earlier row to reference")
+			continue
+		}
+		assert.GreaterOrEqual(t, row["parent_id"], 1)
+		assert.Less(t, row["parent_id"], row["id"], "self-references point at earlier rows")
+	}
+	assert.Len(t, seen, len(customers), "every parent gets referenced")
+}
+
+func TestGenerate_Rules(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   parser.Field
+		check   func(t *testing.T, v any)
+		warning string
+	}{
+		{
+			name:  "oneof",
+			field: parser.Field{Name: "status", Type: "string", Validate: &parser.Rules{OneOf: []string{"active", "banned"}}},
+			check: func(t *testing.T, v any) { assert.Contains(t, []any{"active", "banned"}, v) },
+		},
+		{
+			name:  "int oneof",
+			field: parser.Field{Name: "tier", Type: "int", Validate: &parser.Rules{OneOf: []string{"1", "3"}}},
+			check: func(t *testing.T, v any) { assert.Contains(t, []any{int64(1), int64(3)}, v) },
+		},
+		{
+			name:  "int range",
+			field: parser.Field{Name: "age", Type: "int", Validate: &parser.Rules{Min: ptr(18), Max: ptr(30)}},
+			check: func(t *testing.T, v any) {
+				assert.GreaterOrEqual(t, v, int64(18))
+				assert.LessOrEqual(t, v, int64(30))
+			},
+		},
+		{
+			name:  "negative max",
+			field: parser.Field{Name: "delta", Type: "int64", Validate: &parser.Rules{Max: ptr(-5)}},
+			check: func(t *testing.T, v any) { assert.LessOrEqual(t, v, int64(-5)) },
+		},
+		{
+			name:  "float range",
+			field: parser.Field{Name: "score", Type: "float", Validate: &parser.Rules{Min: ptr(0.5), Max: ptr(1)}},
+			check: func(t *testing.T, v any) {
+				assert.GreaterOrEqual(t, v, 0.5)
+				assert.LessOrEqual(t, v, 1.0)
+			},
+		},
+		{
+			name:  "string length",
+			field: parser.Field{Name: "handle", Type: "string", Unique: true, Validate: &parser.Rules{Min: ptr(12), Max: ptr(14)}},
+			check: func(t *testing.T, v any) {
+				assert.GreaterOrEqual(t, utf8.RuneCountInString(v.(string)), 12)
+				assert.LessOrEqual(t, utf8.RuneCountInString(v.(string)), 14)
+			},
+		},
+		{
+			name:  "short email",
+			field: parser.Field{Name: "contact", Type: "string", Validate: &parser.Rules{Email: true, Max: ptr(16)}},
+			check: func(t *testing.T, v any) {
+				assert.LessOrEqual(t, len(v.(string)), 16)
+				assert.Regexp(t, `^[a-z.]+\d+@example\.com$`, v)
+			},
+		},
+		{
+			name:  "bytes length",
+			field: parser.Field{Name: "blob", Type: "bytes", Validate: &parser.Rules{Min: ptr(32)}},
+			check: func(t *testing.T, v any) {
+				b, err := base64.StdEncoding.DecodeString(v.(string))
+				require.NoError(t, err)
+				assert.Len(t, b, 32)
+			},
+		},
+		{
+			name:    "regex",
+			field:   parser.Field{Name: "code", Type: "string", Validate: &parser.Rules{Regex: `^[A-Z]{3}$`}},
+			warning: `field "code": values matching regex "^[A-Z]{3}$" cannot be generated, left out of the rows`,
+		},
+		{
+			name:    "unique past oneof",
+			field:   parser.Field{Name: "slot", Type: "string", Unique: true, Validate: &parser.Rules{OneOf: []string{"a", "b"}}},
+			check:   func(t *testing.T, v any) { assert.Contains(t, []any{"a", "b"}, v) },
+			warning: `field "slot": unique with 2 oneof values, left out of the rows after the first 2`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, warnings := Generate(parser.Entity{Name: "account", Fields: []parser.Field{tt.field}}, 20, 1, nil)
+
+			if tt.warning != "" {
+				assert.Equal(t, []string{tt.warning}, warnings, "a warning is reported once")
+			} else {
+				assert.Empty(t, warnings)
+			}
+			for _, row := range rows {
+				v, ok := row[tt.field.Name]
+				if tt.check == nil {
+					assert.False(t, ok, "fields whose rules cannot be met are left out")
+					continue
+				}
+				if ok {
+					tt.check(t, v)
+				}
+			}
+		})
+	}
+}
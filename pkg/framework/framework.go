@@ -1,6 +1,10 @@
 package framework
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 type FrameworkConfig struct {
 	Name          string
@@ -13,7 +17,6 @@ type FrameworkConfig struct {
 	Router        string
 	Start         string
 	OtherImports  string
-	ApiGroup      func(entity string, get string, lowerentity string) string
 	Get           string
 	FullContext   string
 	ToTheClient   string
@@ -24,7 +27,54 @@ type FrameworkConfig struct {
 	ReturnKeyword string
 	HTTPHandler   string
 	Entities      []string
+
+	// PathParam and QueryParam read a request parameter, formatted with
+	// its name.
+	PathParam  string
+	QueryParam string
+	// WriteJSON writes a JSON response, formatted with status and body.
+	WriteJSON string
+	// NoContent writes an empty response, formatted with the status.
+	NoContent string
+	// Route registers a handler on r. Paths use {param} placeholders and
+	// are converted to the router's own syntax.
+	Route func(method, path, handler string) string
+	// Mount serves an http.Handler on r at path, for every method.
+	Mount func(path, handler string) string
+	// Buffered routers hold a handler's response back until it returns, so
+	// streams cannot be mounted on them and are served ahead of r instead.
+	Buffered bool
+	// ReturnRouter is what RegisterRoutes returns for the router r.
+	ReturnRouter string
+	// Group is the type of the route group a module registers its routes
+	// on, and MountGroup mounts a module on r, formatted with its variable.
+	Group      string
+	MountGroup string
+	// HelperImports and Helpers are added to the handler package for
+	// routers that need their own response helpers.
+	HelperImports string
+	Helpers       string
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// colonParams rewrites {id} placeholders to the :id syntax.
+func colonParams(path string) string {
+	return pathParam.ReplaceAllString(path, ":$1")
+}
+
+// titleMethod turns GET into Get, as chi and fiber name their methods.
+func titleMethod(method string) string {
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+const writeJSONHelper = `
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
 }
+`
 
 var FrameworkRegistory = map[string]FrameworkConfig{
 	"gin": {
@@ -32,23 +82,17 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 		Imports:      `"github.com/gin-gonic/gin"`,
 		ContextName:  "c",
 		ContextType:  "*gin.Context",
-		Bind:         "c.BindJSON", // func(obj any) error
-		JSON:         "c.JSON",     // func(code int, obj any)
+		Bind:         "c.ShouldBindJSON", // func(obj any) error
+		JSON:         "c.JSON",           // func(code int, obj any)
 		Router:       "*gin.Engine",
 		Start:        "gin.Default()",
 		OtherImports: `"net/http"`,
 		FullContext:  "c *gin.Context",
-		ApiGroup: func(entity string, get string, lowerentity string) string {
-			apiGroup := fmt.Sprintf(`
-				{
-					%s := api.Group("/%s")
-					{
-						%s.%s("", %sHandler.Get%ss)
-					}
-				}
-			`, lowerentity, lowerentity, lowerentity, get, lowerentity, entity)
-
-			return apiGroup
+		Route: func(method, path, handler string) string {
+			return fmt.Sprintf("r.%s(%q, %s)", method, colonParams(path), handler)
+		},
+		Mount: func(path, handler string) string {
+			return fmt.Sprintf("r.Any(%q, gin.WrapH(%s))", path, handler)
 		},
 		Get:         "GET",
 		ToTheClient: "c.JSON(http.StatusOK, ",
@@ -64,6 +108,13 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 		Returnable:    "",
 		ReturnKeyword: "",
 		HTTPHandler:   "http.Handler",
+		PathParam:     `c.Param("%s")`,
+		QueryParam:    `c.Query("%s")`,
+		WriteJSON:     "c.JSON(%s, %s)",
+		NoContent:     "c.Status(%s)",
+		ReturnRouter:  "r",
+		Group:         "*gin.RouterGroup",
+		MountGroup:    `%s.RegisterRoutes(r.Group(""))`,
 	},
 
 	"chi": {
@@ -80,14 +131,11 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 			"github.com/go-chi/render"
 			"net/http"
 		`,
-		ApiGroup: func(entity string, get string, lowerentity string) string {
-			apiGroup := fmt.Sprintf(`
-				r.Group(func(r chi.Router) {
-					r.%s("/%s", handler.Get%ss)
-			})
-			`, get, lowerentity, entity)
-
-			return apiGroup
+		Route: func(method, path, handler string) string {
+			return fmt.Sprintf("r.%s(%q, %s)", titleMethod(method), path, handler)
+		},
+		Mount: func(path, handler string) string {
+			return fmt.Sprintf("r.Handle(%q, %s)", path, handler)
 		},
 		Get:         "Get",
 		FullContext: "w http.ResponseWriter, r *http.Request",
@@ -99,12 +147,24 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 	"github.com/go-chi/chi/v5"
 		`,
 		ImportHandler: `
+			"encoding/json"
 			"net/http"
-			"github.com/go-chi/render"
+			"github.com/go-chi/chi/v5"
 		`,
 		Returnable:    "",
 		ReturnKeyword: "",
 		HTTPHandler:   "http.Handler",
+		PathParam:     `chi.URLParam(r, "%s")`,
+		QueryParam:    `r.URL.Query().Get("%s")`,
+		WriteJSON:     "writeJSON(w, %s, %s)",
+		NoContent:     "w.WriteHeader(%s)",
+		ReturnRouter:  "r",
+		Group:         "chi.Router",
+		MountGroup:    `r.Group(func(r chi.Router) { %s.RegisterRoutes(r) })`,
+		HelperImports: `
+			"encoding/json"
+		`,
+		Helpers: writeJSONHelper,
 	},
 
 	"echo": {
@@ -118,16 +178,11 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 		Start:        "echo.New()",
 		OtherImports: `"net/http"`,
 
-		ApiGroup: func(entity, get, lowerentity string) string {
-			return fmt.Sprintf(`
-            api := r.Group("/api/v1")
-            {
-                %s := api.Group("/%s")
-                {
-                    %s.%s("", handler.Get%ss)
-                }
-            }
-        `, lowerentity, lowerentity, lowerentity, get, entity)
+		Route: func(method, path, handler string) string {
+			return fmt.Sprintf("r.%s(%q, %s)", method, colonParams(path), handler)
+		},
+		Mount: func(path, handler string) string {
+			return fmt.Sprintf("r.Any(%q, echo.WrapHandler(%s))", path, handler)
 		},
 
 		Get: "GET",
@@ -150,6 +205,13 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 		Returnable:    "error",
 		ReturnKeyword: "return",
 		HTTPHandler:   "http.Handler",
+		PathParam:     `c.Param("%s")`,
+		QueryParam:    `c.QueryParam("%s")`,
+		WriteJSON:     "c.JSON(%s, %s)",
+		NoContent:     "c.NoContent(%s)",
+		ReturnRouter:  "r",
+		Group:         "*echo.Group",
+		MountGroup:    `%s.RegisterRoutes(r.Group(""))`,
 	},
 
 	"fiber": {
@@ -163,17 +225,13 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 		Start:        "fiber.New()",
 		OtherImports: `"net/http"`,
 
-		ApiGroup: func(entity, get, lowerentity string) string {
-			return fmt.Sprintf(`
-            api := r.Group("/api/v1")
-            {
-                %s := api.Group("/%s")
-                {
-                    %s.%s("", handler.Get%ss)
-                }
-            }
-        `, lowerentity, lowerentity, lowerentity, get, entity)
+		Route: func(method, path, handler string) string {
+			return fmt.Sprintf("r.%s(%q, %s)", titleMethod(method), colonParams(path), handler)
 		},
+		Mount: func(path, handler string) string {
+			return fmt.Sprintf("r.All(%q, adaptor.HTTPHandler(%s))", path, handler)
+		},
+		Buffered: true,
 
 		Get: "Get",
 
@@ -183,17 +241,29 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 
 		Response: "(fiber.StatusOK,",
 
+		// Fiber is not a net/http router; the adaptor lets the shared
+		// http.Server and graceful shutdown serve it.
 		ImportRouter: `
+        "net/http"
         "github.com/gofiber/fiber/v2"
+        "github.com/gofiber/fiber/v2/middleware/adaptor"
     `,
 
 		ImportHandler: `
+        "net/http"
         "github.com/gofiber/fiber/v2"
     `,
 
 		Returnable:    "error",
 		ReturnKeyword: "return",
-		HTTPHandler:   "*fiber.App",
+		HTTPHandler:   "http.Handler",
+		PathParam:     `c.Params("%s")`,
+		QueryParam:    `c.Query("%s")`,
+		WriteJSON:     "c.Status(%s).JSON(%s)",
+		NoContent:     "c.SendStatus(%s)",
+		ReturnRouter:  "adaptor.FiberApp(r)",
+		Group:         "fiber.Router",
+		MountGroup:    `%s.RegisterRoutes(r.Group(""))`,
 	},
 
 	"mux": {
@@ -209,5 +279,39 @@ var FrameworkRegistory = map[string]FrameworkConfig{
 			"encoding/json"
 			"net/http"
 		`,
+		Route: func(method, path, handler string) string {
+			return fmt.Sprintf("r.HandleFunc(%q, %s).Methods(%q)", path, handler, method)
+		},
+		Mount: func(path, handler string) string {
+			return fmt.Sprintf("r.Handle(%q, %s)", path, handler)
+		},
+		Get:         "GET",
+		FullContext: "w http.ResponseWriter, r *http.Request",
+		ToTheClient: "json.NewEncoder(w).Encode(",
+		Response:    "(w, r,",
+		ImportRouter: `
+			"encoding/json"
+	"net/http"
+	"github.com/gorilla/mux"
+		`,
+		ImportHandler: `
+			"encoding/json"
+			"net/http"
+			"github.com/gorilla/mux"
+		`,
+		Returnable:    "",
+		ReturnKeyword: "",
+		HTTPHandler:   "http.Handler",
+		PathParam:     `mux.Vars(r)["%s"]`,
+		QueryParam:    `r.URL.Query().Get("%s")`,
+		WriteJSON:     "writeJSON(w, %s, %s)",
+		NoContent:     "w.WriteHeader(%s)",
+		ReturnRouter:  "r",
+		Group:         "*mux.Router",
+		MountGroup:    `%s.RegisterRoutes(r)`,
+		HelperImports: `
+			"encoding/json"
+		`,
+		Helpers: writeJSONHelper,
 	},
 }
@@ -0,0 +1,367 @@
+// Package layout lists the kinds of project that can be generated and, for
+// each kind, the architectures its code can be laid out in.
+package layout
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultType is the project type used when neither the spec nor a flag
+// names one.
+const DefaultType = "rest"
+
+// TypeConfig is one kind of project, such as a REST API.
+type TypeConfig struct {
+	// DefaultArch is used when neither the spec nor a flag names one.
+	DefaultArch string
+	// HTTP types serve through one of the routers, and only they take a
+	// router and features.
+	HTTP bool
+	// Healthcheck is the compose healthcheck test of the app container.
+	// Types without one run no server, so take no port and get no container.
+	Healthcheck []string
+	// Entities types generate code for the entities of the spec.
+	Entities bool
+	// Databases types keep data in the datastores of the spec; the others
+	// take none.
+	Databases bool
+	// Commands types build a command-line tool out of the commands of the
+	// spec.
+	Commands bool
+	// Jobs types run the jobs of the spec off a queue.
+	Jobs bool
+	// Realtime types stream the changes of realtime entities to clients.
+	Realtime bool
+	Archs    map[string]ArchConfig
+}
+
+// ArchConfig is one architecture of a project type.
+type ArchConfig struct {
+	// TemplateDirs hold the templates of the architecture, relative to the
+	// templates FS. They are rendered in order into the project root.
+	TemplateDirs []string
+	// ModelPackage is the package, relative to the module, whose Registry
+	// lists the models each datastore migrates (empty when the app migrates
+	// them itself), and DBDir the directory the database package is
+	// generated in.
+	ModelPackage string
+	DBDir        string
+	// Main is the package holding the app's main function, empty for
+	// libraries, which build no binary and are released by tagging. Seed
+	// is the arguments of the `go run` that upserts the seed data.
+	Main string
+	Seed string
+	// ProtoDir holds the .proto files of the API, whose Go code is
+	// generated along with the project. Empty for architectures without.
+	ProtoDir string
+	// RealtimeDir holds the adapters between the app and the event bus,
+	// rendered into projects with realtime entities. Empty for
+	// architectures whose services publish to the bus directly.
+	RealtimeDir string
+	// Batch adds repository and service methods that load the records of
+	// many IDs at once, which the GraphQL resolvers batch relations with.
+	Batch bool
+	// Version is the package, relative to the module, whose version, commit
+	// and build date `make build` stamps through -ldflags. Empty for
+	// architectures without.
+	Version string
+	// Packages are the packages the layers of each entity are generated in,
+	// for the templates the layered architectures share.
+	Packages    Packages
+	Description string
+}
+
+// Package is a package of the generated code.
+type Package struct {
+	// Path is the import path of the package, relative to the module.
+	Path string
+	Name string
+}
+
+// pkg returns the package at path, named after its last element.
+func pkg(p string) Package {
+	return Package{Path: p, Name: path.Base(p)}
+}
+
+// Import returns the import spec of p in module, naming the package when
+// its name is not the last element of its path.
+func (p Package) Import(module string) string {
+	spec := `"` + module + "/" + p.Path + `"`
+	if p.Name != path.Base(p.Path) {
+		spec = p.Name + " " + spec
+	}
+	return spec
+}
+
+// Ref returns the qualifier of p's identifiers in the code of package from,
+// which is empty within p itself.
+func (p Package) Ref(from Package) string {
+	if p.Path == from.Path {
+		return ""
+	}
+	return p.Name + "."
+}
+
+// Packages are the packages an architecture lays the layers of each entity
+// out in. In modular projects their paths hold ModulePlaceholder. The templates shared between architectures
+// import them, and are rendered into them from the directories listed in
+// Dir.
+type Packages struct {
+	// Server runs the HTTP API, whose handlers Handler holds, binding the
+	// requests of DTO.
+	Server  Package
+	Handler Package
+	DTO     Package
+	// Service declares the service interfaces, and Services implements them.
+	Service  Package
+	Services Package
+	// Repository declares the repository interfaces, which Store implements
+	// over GORM and Memory in memory, returning the errors of Errors.
+	Repository Package
+	Store      Package
+	Memory     Package
+	Errors     Package
+	// Model holds the entities, and Events what the services publish their
+	// changes with.
+	Model  Package
+	Events Package
+}
+
+// Dir returns the path of the package the shared templates under the
+// directory name are rendered into, and whether name is one of those
+// directories. The path is empty for packages the architecture lacks.
+func (p Packages) Dir(name string) (string, bool) {
+	switch name {
+	case "server":
+		return p.Server.Path, true
+	case "handler":
+		return p.Handler.Path, true
+	case "services":
+		return p.Services.Path, true
+	case "store":
+		return p.Store.Path, true
+	case "memory":
+		return p.Memory.Path, true
+	case "errors":
+		return p.Errors.Path, true
+	}
+	return "", false
+}
+
+// In returns the packages of the entities of module.
+func (p Packages) In(module string) Packages {
+	for _, pkg := range []*Package{
+		&p.Server, &p.Handler, &p.DTO, &p.Service, &p.Services, &p.Repository,
+		&p.Store, &p.Memory, &p.Errors, &p.Model, &p.Events,
+	} {
+		pkg.Path = strings.Replace(pkg.Path, ModulePlaceholder, module, 1)
+	}
+	return p
+}
+
+// ModulePlaceholder stands for the module of the entity in the paths of
+// modular projects.
+const ModulePlaceholder = "modulename"
+
+// cleanPackages are the layers of the clean architecture, which REST, gRPC
+// and GraphQL projects share.
+var cleanPackages = Packages{
+	Service:    pkg("internal/service"),
+	Services:   pkg("internal/service"),
+	Repository: pkg("internal/repository"),
+	Store:      pkg("internal/repository"),
+	Memory:     pkg("internal/repository/memory"),
+	Errors:     pkg("internal/repository"),
+	Model:      pkg("internal/model"),
+	Events:     pkg("internal/events"),
+}
+
+// serving returns p with the packages of a REST API.
+func (p Packages) serving(server, handler, dto Package) Packages {
+	p.Server, p.Handler, p.DTO = server, handler, dto
+	return p
+}
+
+// TypeRegistory maps project types, then architectures, to the templates
+// that generate them.
+var TypeRegistory = map[string]TypeConfig{
+	"rest": {
+		DefaultArch: "clean",
+		HTTP:        true,
+		Healthcheck: []string{"CMD", "wget", "-qO-", "http://localhost:${PORT}/health"},
+		Entities:    true,
+		Databases:   true,
+		Realtime:    true,
+		Archs: map[string]ArchConfig{
+			"clean": {
+				TemplateDirs: []string{"shared", "rest/shared", "layers/shared", "layers/clean", "rest/layered", "rest/clean"},
+				ModelPackage: "internal/model",
+				DBDir:        "internal/db",
+				Main:         "./cmd",
+				Seed:         "./cmd/seed",
+				Packages:     cleanPackages.serving(Package{Path: "internal/server", Name: "router"}, pkg("internal/handler"), pkg("internal/dto")),
+				Description:  "handler, service and repository layers under internal/",
+			},
+			"hexagonal": {
+				TemplateDirs: []string{"shared", "rest/shared", "layers/shared", "rest/layered", "rest/hexagonal"},
+				ModelPackage: "internal/adapters/persistence",
+				DBDir:        "internal/adapters/persistence/db",
+				Main:         "./cmd",
+				Seed:         "./cmd/seed",
+				RealtimeDir:  "realtime/hexagonal",
+				Packages: Packages{
+					Server:     Package{Path: "internal/adapters/http", Name: "httpadapter"},
+					Handler:    Package{Path: "internal/adapters/http", Name: "httpadapter"},
+					DTO:        Package{Path: "internal/adapters/http", Name: "httpadapter"},
+					Service:    pkg("internal/core/ports"),
+					Services:   pkg("internal/core/services"),
+					Repository: pkg("internal/core/ports"),
+					Store:      pkg("internal/adapters/persistence"),
+					Memory:     pkg("internal/adapters/persistence/memory"),
+					Errors:     pkg("internal/core/domain"),
+					Model:      pkg("internal/core/domain"),
+					Events:     pkg("internal/core/ports"),
+				},
+				Description: "core domain and ports, with http and persistence adapters",
+			},
+			"modular": {
+				TemplateDirs: []string{"shared", "rest/shared", "layers/shared", "rest/layered", "rest/modular"},
+				ModelPackage: "internal/modules",
+				DBDir:        "internal/db",
+				Main:         "./cmd",
+				Seed:         "./cmd/seed",
+				Packages: Packages{
+					Server:     Package{Path: "internal/server", Name: "router"},
+					Handler:    pkg("internal/modules/" + ModulePlaceholder + "/handler"),
+					DTO:        pkg("internal/modules/" + ModulePlaceholder + "/handler"),
+					Service:    pkg("internal/modules/" + ModulePlaceholder + "/service"),
+					Services:   pkg("internal/modules/" + ModulePlaceholder + "/service"),
+					Repository: pkg("internal/modules/" + ModulePlaceholder + "/repository"),
+					Store:      pkg("internal/modules/" + ModulePlaceholder + "/repository"),
+					Memory:     pkg("internal/modules/" + ModulePlaceholder + "/repository/memory"),
+					Errors:     pkg("internal/modules/" + ModulePlaceholder + "/repository"),
+					Model:      pkg("internal/modules/" + ModulePlaceholder + "/model"),
+					Events:     pkg("internal/events"),
+				},
+				Description: "bounded-context modules under internal/modules, each with its own layers",
+			},
+			"minimal": {
+				TemplateDirs: []string{"shared", "rest/shared", "rest/minimal"},
+				DBDir:        "internal/db",
+				Main:         ".",
+				Seed:         ". seed",
+				Description:  "a main.go and one file per entity, handlers using storage directly",
+			},
+		},
+	},
+	"grpc": {
+		DefaultArch: "clean",
+		Healthcheck: []string{"CMD", "/app", "healthcheck"},
+		Entities:    true,
+		Databases:   true,
+		Archs: map[string]ArchConfig{
+			"clean": {
+				TemplateDirs: []string{"shared", "layers/shared", "layers/clean", "grpc/clean"},
+				Packages:     cleanPackages,
+				ModelPackage: "internal/model",
+				DBDir:        "internal/db",
+				Main:         "./cmd",
+				Seed:         "./cmd/seed",
+				ProtoDir:     "proto",
+				Description:  "protobuf services under proto/, served over the clean service and repository layers",
+			},
+		},
+	},
+	"graphql": {
+		DefaultArch: "clean",
+		HTTP:        true,
+		Healthcheck: []string{"CMD", "wget", "-qO-", "http://localhost:${PORT}/health"},
+		Entities:    true,
+		Databases:   true,
+		Archs: map[string]ArchConfig{
+			"clean": {
+				TemplateDirs: []string{"shared", "layers/shared", "layers/clean", "graphql/clean"},
+				Packages:     cleanPackages,
+				ModelPackage: "internal/model",
+				DBDir:        "internal/db",
+				Main:         "./cmd",
+				Seed:         "./cmd/seed",
+				Batch:        true,
+				Description:  "a GraphQL schema and resolvers under internal/graph, over the clean service and repository layers",
+			},
+		},
+	},
+	"worker": {
+		DefaultArch: "queue",
+		Healthcheck: []string{"CMD", "wget", "-qO-", "http://localhost:${PORT}/health"},
+		Databases:   true,
+		Jobs:        true,
+		Archs: map[string]ArchConfig{
+			"queue": {
+				TemplateDirs: []string{"shared", "worker/queue"},
+				DBDir:        "internal/db",
+				Main:         "./cmd",
+				Description:  "typed job handlers under internal/jobs, run by a worker pool off the queue in internal/queue",
+			},
+		},
+	},
+	"cli": {
+		DefaultArch: "cobra",
+		Commands:    true,
+		Archs: map[string]ArchConfig{
+			"cobra": {
+				TemplateDirs: []string{"cli/cobra"},
+				Main:         ".",
+				Version:      "internal/version",
+				Description:  "a cobra command tree under cmd/, one file per command, with config file loading and golden tests",
+			},
+		},
+	},
+	"library": {
+		DefaultArch: "module",
+		Archs: map[string]ArchConfig{
+			"module": {
+				TemplateDirs: []string{"library/module"},
+				Description:  "a package at the module root with godoc examples and benchmarks, its helpers under internal/, released by semver tags",
+			},
+		},
+	},
+}
+
+// Types returns the known project types, sorted.
+func Types() []string {
+	return sortedNames(TypeRegistory)
+}
+
+// Archs returns the architectures of a project type, sorted.
+func Archs(projectType string) []string {
+	return sortedNames(TypeRegistory[projectType].Archs)
+}
+
+// Lookup returns the architecture arch of projectType. Empty values select
+// the defaults.
+func Lookup(projectType, arch string) (ArchConfig, bool) {
+	if projectType == "" {
+		projectType = DefaultType
+	}
+	t, ok := TypeRegistory[projectType]
+	if !ok {
+		return ArchConfig{}, false
+	}
+	if arch == "" {
+		arch = t.DefaultArch
+	}
+	a, ok := t.Archs[arch]
+	return a, ok
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
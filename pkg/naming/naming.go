@@ -0,0 +1,113 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words splits an identifier written in snake_case, kebab-case, camelCase or
+// PascalCase into lower-case words.
+func Words(s string) []string {
+	var words []string
+	var cur []rune
+
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			flush()
+		case unicode.IsUpper(r):
+			// Start a new word on "aB" and on the last upper of "ABc".
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))) {
+				flush()
+			}
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// commonInitialisms are upper-cased as a whole when they form a word, so that
+// "user_id" becomes "UserID" as golint expects.
+var commonInitialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "uuid": true, "api": true, "http": true,
+	"ip": true, "json": true, "sql": true, "html": true, "db": true,
+}
+
+// Pascal converts s to an exported Go identifier: "order_item" -> "OrderItem".
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, w := range Words(s) {
+		if commonInitialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// Camel converts s to an unexported Go identifier: "order_item" -> "orderItem".
+func Camel(s string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0] + strings.TrimPrefix(Pascal(s), Pascal(words[0]))
+}
+
+// Snake converts s to snake_case: "OrderItem" -> "order_item".
+func Snake(s string) string {
+	return strings.Join(Words(s), "_")
+}
+
+// Plural returns a naive English plural of the last word of s.
+func Plural(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "z"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
+
+// Singular reverses Plural for the common cases, used to turn table names
+// back into entity names.
+func Singular(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "zes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"):
+		return s
+	case strings.HasSuffix(lower, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	default:
+		return s
+	}
+}
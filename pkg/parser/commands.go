@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+	"gopkg.in/yaml.v3"
+)
+
+// Command is one subcommand of a CLI project. It may be written either as a
+// plain name (`- serve`) or as an object with positional args and flags.
+type Command struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Args        []string `yaml:"args,omitempty"`
+	Flags       []Flag   `yaml:"flags,omitempty"`
+}
+
+// Flag is a flag of a command. Its value may also come from the
+// environment or from the config file of the CLI.
+type Flag struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"`
+	Short       string `yaml:"short,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+func (c *Command) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Name = node.Value
+		return nil
+	}
+
+	type plain Command
+	return node.Decode((*plain)(c))
+}
+
+func (c Command) MarshalYAML() (interface{}, error) {
+	if c.Description == "" && len(c.Args) == 0 && len(c.Flags) == 0 {
+		return c.Name, nil
+	}
+
+	type plain Command
+	return plain(c), nil
+}
+
+// FlagTypes maps the flag types to the parsers of their defaults. Flags
+// without a type are strings.
+var FlagTypes = map[string]func(string) error{
+	"string": func(string) error { return nil },
+	"int": func(s string) error {
+		_, err := strconv.Atoi(s)
+		return err
+	},
+	"bool": func(s string) error {
+		_, err := strconv.ParseBool(s)
+		return err
+	},
+	"duration": func(s string) error {
+		_, err := time.ParseDuration(s)
+		return err
+	},
+	// strings are lists, written comma-separated.
+	"strings": func(s string) error {
+		if strings.Contains(s, `"`) {
+			return fmt.Errorf("quotes are not supported")
+		}
+		return nil
+	},
+}
+
+// FlagType is the type of the flag, defaulting to string.
+func (f Flag) FlagType() string {
+	if f.Type == "" {
+		return "string"
+	}
+	return f.Type
+}
+
+var (
+	commandName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+	argName     = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	shortName   = regexp.MustCompile(`^[a-zA-Z]$`)
+)
+
+// reservedCommands and reservedFlags are added to every generated CLI.
+var (
+	reservedCommands = []string{"help", "completion", "version"}
+	reservedFlags    = []string{"help", "config", "version"}
+)
+
+// checkCommands reports commands that cannot be generated: invalid or
+// duplicate names, and args and flags that would clash in the options of
+// their command or whose defaults do not parse.
+func (c *Config) checkCommands(doc *yaml.Node) []Issue {
+	var issues []Issue
+	add := func(node *yaml.Node, format string, args ...any) {
+		issues = append(issues, nodeIssue(node, fmt.Sprintf(format, args...)))
+	}
+
+	names := map[string]bool{}
+	for i, command := range c.Commands {
+		switch {
+		case command.Name == "":
+			add(lookup(doc, "commands", i), "command without a name")
+			continue
+		case !commandName.MatchString(command.Name):
+			add(lookup(doc, "commands", i, "name"), "command name %q must be lowercase words separated by dashes", command.Name)
+		case contains(reservedCommands, command.Name):
+			add(lookup(doc, "commands", i, "name"), "command name %q is reserved", command.Name)
+		case names[command.Name]:
+			add(lookup(doc, "commands", i, "name"), "command %q is declared twice", command.Name)
+		}
+		names[command.Name] = true
+
+		// Args and flags are both fields of the command's options.
+		fields := map[string]bool{}
+		for j, arg := range command.Args {
+			node := lookup(doc, "commands", i, "args", j)
+			switch {
+			case !argName.MatchString(arg):
+				add(node, "command %q: arg name %q must be lowercase letters, digits and underscores, starting with a letter", command.Name, arg)
+			case fields[naming.Pascal(arg)]:
+				add(node, "command %q: arg %q is declared twice", command.Name, arg)
+			}
+			fields[naming.Pascal(arg)] = true
+		}
+
+		shorts := map[string]bool{}
+		for j, flag := range command.Flags {
+			where := []any{"commands", i, "flags", j}
+			switch {
+			case flag.Name == "":
+				add(lookup(doc, where...), "command %q: flag without a name", command.Name)
+				continue
+			case !commandName.MatchString(flag.Name):
+				add(lookup(doc, append(where, "name")...), "command %q: flag name %q must be lowercase words separated by dashes", command.Name, flag.Name)
+			case contains(reservedFlags, flag.Name):
+				add(lookup(doc, append(where, "name")...), "command %q: flag name %q is reserved", command.Name, flag.Name)
+			case fields[naming.Pascal(flag.Name)]:
+				add(lookup(doc, append(where, "name")...), "command %q: %q is declared twice among its args and flags", command.Name, flag.Name)
+			}
+			fields[naming.Pascal(flag.Name)] = true
+
+			if flag.Short != "" {
+				switch {
+				case !shortName.MatchString(flag.Short) || flag.Short == "h":
+					add(lookup(doc, append(where, "short")...), "command %q: short flag %q must be a letter other than h", command.Name, flag.Short)
+				case shorts[flag.Short]:
+					add(lookup(doc, append(where, "short")...), "command %q: short flag %q is used twice", command.Name, flag.Short)
+				}
+				shorts[flag.Short] = true
+			}
+
+			parse, ok := FlagTypes[flag.FlagType()]
+			if !ok {
+				add(lookup(doc, append(where, "type")...), "command %q: unknown flag type %q (expected one of %s)",
+					command.Name, flag.Type, strings.Join(sortedKeys(FlagTypes), ", "))
+				continue
+			}
+			if flag.Default != "" {
+				if err := parse(flag.Default); err != nil {
+					add(lookup(doc, append(where, "default")...), "command %q: default %q of flag %q is not a valid %s",
+						command.Name, flag.Default, flag.Name, flag.FlagType())
+				}
+			}
+		}
+	}
+	return issues
+}
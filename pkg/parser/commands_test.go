@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_CheckCommands(t *testing.T) {
+	const project = "project:\n  type: cli\ncommands:\n"
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "valid", spec: `
+  - ping
+  - name: list-users
+    args: [org_id]
+    flags:
+      - { name: limit, type: int, short: l, default: "20" }
+      - { name: tags, type: strings, default: "a,b" }
+      - { name: timeout, type: duration, default: 1m }
+`},
+		{name: "invalid name", spec: "  - ListUsers\n",
+			wantErr: `p.yaml:4:5: command name "ListUsers" must be lowercase words separated by dashes`},
+		{name: "reserved name", spec: "  - version\n",
+			wantErr: `p.yaml:4:5: command name "version" is reserved`},
+		{name: "duplicate", spec: "  - ping\n  - name: ping\n",
+			wantErr: `p.yaml:5:11: command "ping" is declared twice`},
+		{name: "arg and flag clash", spec: "  - name: get\n    args: [id]\n    flags: [{ name: id }]\n",
+			wantErr: `p.yaml:6:21: command "get": "id" is declared twice among its args and flags`},
+		{name: "reserved flag", spec: "  - name: get\n    flags: [{ name: config }]\n",
+			wantErr: `p.yaml:5:21: command "get": flag name "config" is reserved`},
+		{name: "short h", spec: "  - name: get\n    flags: [{ name: host, short: h }]\n",
+			wantErr: `p.yaml:5:34: command "get": short flag "h" must be a letter other than h`},
+		{name: "unknown type", spec: "  - name: get\n    flags: [{ name: n, type: float }]\n",
+			wantErr: `p.yaml:5:30: command "get": unknown flag type "float" (expected one of bool, duration, int, string, strings)`},
+		{name: "bad default", spec: "  - name: get\n    flags: [{ name: n, type: int, default: ten }]\n",
+			wantErr: `p.yaml:5:44: command "get": default "ten" of flag "n" is not a valid int`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := DecodeYAML([]byte(project+tt.spec), "p.yaml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Len(t, config.Commands, 2)
+				assert.Equal(t, "ping", config.Commands[0].Name)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AppendTag marks a list that is appended to the list it overrides in an
+// extended spec, instead of replacing it:
+//
+//	entities: !append
+//	  - invoice
+const AppendTag = "!append"
+
+// spec is a project file merged with every file it extends. origins maps
+// each node of doc to the file it was read from.
+type spec struct {
+	doc     *yaml.Node
+	origins map[*yaml.Node]string
+}
+
+// loadSpec parses a project file and, following its extends key, the
+// files it builds on, then merges them into one document. Paths in extends
+// are relative to the file that names them.
+func loadSpec(data []byte, file string) (*spec, error) {
+	s := &spec{origins: map[*yaml.Node]string{}}
+
+	var chain []*yaml.Node // the file itself first, then what it extends
+	var files []string
+	seen := map[string]bool{}
+	for {
+		doc, err := s.parse(data, file)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, doc)
+		files = append(files, file)
+		if abs, err := filepath.Abs(file); err == nil {
+			seen[abs] = true
+		}
+
+		base, node, err := extendsPath(doc, file)
+		if err != nil {
+			return nil, err
+		}
+		if base == "" {
+			break
+		}
+		abs, _ := filepath.Abs(base)
+		if seen[abs] {
+			return nil, s.fail(node, "extends cycle: %s -> %s", strings.Join(files, " -> "), base)
+		}
+		if data, err = os.ReadFile(base); err != nil {
+			return nil, s.fail(node, "cannot read extended spec: %v", err)
+		}
+		file = base
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		s.doc = s.merge(s.doc, chain[i])
+	}
+	return s, nil
+}
+
+// parse reads one file of the chain, records where its nodes come from
+// and checks its !append tags.
+func (s *spec) parse(data []byte, file string) (*yaml.Node, error) {
+	doc, err := parseDocument(data, file)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, &ValidationError{File: file, Issues: []Issue{{Line: 1, Message: "file is empty"}}}
+	}
+
+	var issues []Issue
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		s.origins[n] = file
+		if n.Tag == AppendTag && n.Kind != yaml.SequenceNode {
+			issues = append(issues, Issue{File: file, Line: n.Line, Column: n.Column, Message: AppendTag + " applies to lists only"})
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(doc)
+	if len(issues) > 0 {
+		return nil, &ValidationError{File: file, Issues: issues}
+	}
+	return doc, nil
+}
+
+// extendsPath removes the extends key from doc and returns the path it
+// names, resolved against file, and its node.
+func extendsPath(doc *yaml.Node, file string) (string, *yaml.Node, error) {
+	if doc.Kind != yaml.MappingNode {
+		return "", nil, nil
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "extends" {
+			continue
+		}
+		value := doc.Content[i+1]
+		doc.Content = append(doc.Content[:i:i], doc.Content[i+2:]...)
+
+		issue := func(msg string) error {
+			return &ValidationError{File: file, Issues: []Issue{{Line: value.Line, Column: value.Column, Message: msg}}}
+		}
+		switch {
+		case value.Kind != yaml.ScalarNode || value.Value == "":
+			return "", nil, issue("extends must be the path of a spec file")
+		case strings.Contains(value.Value, "://"):
+			return "", nil, issue(fmt.Sprintf("extends %s: only local paths are supported", value.Value))
+		}
+		path := value.Value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+		return path, value, nil
+	}
+	return "", nil, nil
+}
+
+// merge overlays over on base: mappings merge key by key, lists tagged
+// !append extend the base list, and anything else replaces it.
+func (s *spec) merge(base, over *yaml.Node) *yaml.Node {
+	switch {
+	case base == nil:
+		return s.untag(over)
+
+	case base.Kind == yaml.MappingNode && over.Kind == yaml.MappingNode:
+		out := *over
+		out.Content = append([]*yaml.Node(nil), base.Content...)
+		s.origins[&out] = s.origins[over]
+		for i := 0; i+1 < len(over.Content); i += 2 {
+			key, value := over.Content[i], over.Content[i+1]
+			j := indexOfKey(&out, key.Value)
+			if j < 0 {
+				out.Content = append(out.Content, key, s.untag(value))
+				continue
+			}
+			out.Content[j] = key
+			out.Content[j+1] = s.merge(out.Content[j+1], value)
+		}
+		return &out
+
+	case base.Kind == yaml.SequenceNode && over.Kind == yaml.SequenceNode && over.Tag == AppendTag:
+		out := *s.untag(over)
+		out.Content = append(append([]*yaml.Node(nil), base.Content...), over.Content...)
+		s.origins[&out] = s.origins[over]
+		return &out
+	}
+	return s.untag(over)
+}
+
+// untag drops the !append tags of n, which has nothing left to append to.
+func (s *spec) untag(n *yaml.Node) *yaml.Node {
+	if n.Tag == AppendTag {
+		n.Tag = ""
+	}
+	for _, child := range n.Content {
+		s.untag(child)
+	}
+	return n
+}
+
+func indexOfKey(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// fail reports an issue at node, in the file it was read from.
+func (s *spec) fail(node *yaml.Node, format string, args ...any) error {
+	file := s.origins[node]
+	return &ValidationError{File: file, Issues: []Issue{{File: file, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)}}}
+}
+
+// ResolveYAML reads a project file and every file it extends, and returns
+// the merged spec as YAML. YAML files that extend nothing are returned as
+// is.
+func ResolveYAML(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := loadSpec(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if !s.extends() && FormatOf(path) == FormatYAML {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(s.doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// extends reports whether the spec was merged from several files.
+func (s *spec) extends() bool {
+	files := map[string]bool{}
+	for _, file := range s.origins {
+		files[file] = true
+	}
+	return len(files) > 1
+}
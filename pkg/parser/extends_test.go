@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSpecs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestReadYAML_Extends(t *testing.T) {
+	dir := writeSpecs(t, map[string]string{
+		"org/base.yaml": "project: { router: chi, port: 9000, db: postgres }\nfeatures: { auth: jwt }\nprofiles: [dev]\nentities: [audit]\n",
+		"team.yaml":     "extends: org/base.yaml\nproject: { db: sqlite }\nprofiles: [prod]\n",
+		"project.yaml":  "extends: team.yaml\nproject: { name: shop }\nentities: !append\n  - user\n",
+	})
+
+	config, err := ReadYAML(filepath.Join(dir, "project.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, Project{Name: "shop", Router: "chi", Port: 9000, Database: "sqlite"}, config.Project)
+	assert.Equal(t, "jwt", config.Features.Auth.Name, "maps merge")
+	assert.Equal(t, []Profile{{Name: "prod"}}, config.Profiles, "lists replace")
+	assert.Equal(t, []string{"audit", "user"}, config.EntityNames(), "!append lists extend")
+
+	resolved, err := ResolveYAML(filepath.Join(dir, "project.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `project: {router: chi, port: 9000, db: sqlite, name: shop}
+features: {auth: jwt}
+profiles: [prod]
+entities:
+  - audit
+  - user
+`, string(resolved))
+}
+
+func TestReadYAML_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{name: "issue in base", files: map[string]string{
+			"base.yaml":    "project:\n  router: gim\n",
+			"project.yaml": "extends: base.yaml\nproject: { name: shop }\n",
+		}, wantErr: `base.yaml:2:11: unknown router "gim" (expected one of chi, echo, fiber, gin, mux)`},
+		{name: "missing base", files: map[string]string{
+			"project.yaml": "extends: nowhere.yaml\n",
+		}, wantErr: "project.yaml:1:10: cannot read extended spec"},
+		{name: "cycle", files: map[string]string{
+			"a.yaml":       "extends: project.yaml\n",
+			"project.yaml": "extends: a.yaml\n",
+		}, wantErr: "a.yaml:1:10: extends cycle: "},
+		{name: "remote", files: map[string]string{
+			"project.yaml": "extends: https://example.com/base.yaml\n",
+		}, wantErr: "project.yaml:1:10: extends https://example.com/base.yaml: only local paths are supported"},
+		{name: "append on a map", files: map[string]string{
+			"project.yaml": "project: !append { name: shop }\n",
+		}, wantErr: "project.yaml:1:10: !append applies to lists only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeSpecs(t, tt.files)
+			_, err := ReadYAML(filepath.Join(dir, "project.yaml"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
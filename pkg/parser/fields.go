@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+)
+
+// FieldType describes how a project.yaml field type is generated.
+type FieldType struct {
+	Go     string            // Go type of the model field
+	Import string            // package the Go type needs, if any
+	Tag    string            // extra gorm tag settings
+	SQL    map[string]string // column type per GORM dialect
+	// Nilable types already have a zero value meaning "absent", so nullable
+	// fields of these types are not turned into pointers.
+	Nilable bool
+}
+
+var FieldTypes = map[string]FieldType{
+	"string": {Go: "string", Tag: "size:255",
+		SQL: map[string]string{"postgres": "varchar(255)", "mysql": "varchar(255)", "sqlite": "text"}},
+	"text": {Go: "string",
+		SQL: map[string]string{"postgres": "text", "mysql": "text", "sqlite": "text"}},
+	"int": {Go: "int",
+		SQL: map[string]string{"postgres": "integer", "mysql": "int", "sqlite": "integer"}},
+	"int64": {Go: "int64",
+		SQL: map[string]string{"postgres": "bigint", "mysql": "bigint", "sqlite": "integer"}},
+	"float": {Go: "float64",
+		SQL: map[string]string{"postgres": "double precision", "mysql": "double", "sqlite": "real"}},
+	"decimal": {Go: "float64", Tag: "type:decimal(12,2)",
+		SQL: map[string]string{"postgres": "numeric(12,2)", "mysql": "decimal(12,2)", "sqlite": "numeric"}},
+	"bool": {Go: "bool",
+		SQL: map[string]string{"postgres": "boolean", "mysql": "boolean", "sqlite": "boolean"}},
+	"time": {Go: "time.Time", Import: "time",
+		SQL: map[string]string{"postgres": "timestamptz", "mysql": "datetime(3)", "sqlite": "datetime"}},
+	"uuid": {Go: "string", Tag: "size:36",
+		SQL: map[string]string{"postgres": "uuid", "mysql": "char(36)", "sqlite": "text"}},
+	"json": {Go: "json.RawMessage", Import: "encoding/json", Tag: "serializer:json", Nilable: true,
+		SQL: map[string]string{"postgres": "jsonb", "mysql": "json", "sqlite": "text"}},
+	"bytes": {Go: "[]byte", Nilable: true,
+		SQL: map[string]string{"postgres": "bytea", "mysql": "blob", "sqlite": "blob"}},
+}
+
+// FieldTypeNames returns the supported field types, sorted.
+func FieldTypeNames() []string {
+	names := make([]string, 0, len(FieldTypes))
+	for name := range FieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReservedFields are the columns every generated model already has.
+var ReservedFields = []string{"id", "created_at", "updated_at", "deleted_at"}
+
+// ReservedEntities are the names, in lower case, that the generated code
+// already gives to packages, types, receivers and variables in the scope of
+// every entity, so that an entity named so would shadow them.
+var ReservedEntities = []string{
+	"append", "body", "clause", "complex128", "complex64", "ctx", "domain", "err", "errors",
+	"float32", "float64", "fmt", "gorm", "h", "http", "id", "int16", "int32", "int64", "int8",
+	"len", "model", "nil", "ok", "preload", "r", "record", "repository", "req", "s", "t",
+	"true", "tt", "uint16", "uint32", "uint64", "uint8", "w",
+}
+
+// CheckEntityName reports whether name can name an entity: a Go identifier
+// that is not one of ReservedEntities.
+func CheckEntityName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("entity without a name")
+	case !token.IsIdentifier(name):
+		return fmt.Errorf("entity name %q is not a valid Go identifier", name)
+	case contains(ReservedEntities, strings.ToLower(name)):
+		return fmt.Errorf("entity name %q is reserved by the generated code", name)
+	}
+	return nil
+}
+
+// CheckEntityNames reports the first name that CheckEntityName rejects or
+// that repeats another one, ignoring case, as the generated files do.
+func CheckEntityNames(names []string) error {
+	seen := map[string]bool{}
+	for _, name := range names {
+		if err := CheckEntityName(name); err != nil {
+			return err
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("entity %q is declared twice", name)
+		}
+		seen[strings.ToLower(name)] = true
+	}
+	return nil
+}
+
+// JSONName is the key of the field in request and response bodies.
+func (f Field) JSONName() string {
+	if f.JSON != "" {
+		return f.JSON
+	}
+	return naming.Snake(f.Name)
+}
+
+// CheckFields reports the first problem in the entity's field list: invalid
+// or duplicate names, reserved names, unknown types and defaults that cannot
+// be written into a struct tag.
+func (e Entity) CheckFields() error {
+	return checkFields(fmt.Sprintf("entity %q", e.Name), e.Fields, ReservedFields)
+}
+
+// checkFields reports the first problem in the fields of owner, e.g.
+// `entity "user"`, none of which may be named as one of reserved.
+func checkFields(owner string, fields []Field, reserved []string) error {
+	seen := map[string]string{}
+	for _, name := range reserved {
+		seen[name] = "a built-in model field"
+	}
+
+	for _, field := range fields {
+		column := naming.Snake(field.Name)
+		switch {
+		case field.Name == "":
+			return fmt.Errorf("%s: field without a name", owner)
+		case !token.IsIdentifier(naming.Pascal(field.Name)):
+			return fmt.Errorf("%s: field %q is not a valid identifier", owner, field.Name)
+		case seen[column] != "":
+			return fmt.Errorf("%s: field %q clashes with %s", owner, field.Name, seen[column])
+		}
+		seen[column] = fmt.Sprintf("field %q", field.Name)
+
+		if field.Type == "" {
+			return fmt.Errorf("%s: field %q has no type (expected one of %s)",
+				owner, field.Name, strings.Join(FieldTypeNames(), ", "))
+		}
+		if _, ok := FieldTypes[field.Type]; !ok {
+			return fmt.Errorf("%s: field %q has unknown type %q (expected one of %s)",
+				owner, field.Name, field.Type, strings.Join(FieldTypeNames(), ", "))
+		}
+		if strings.ContainsAny(field.Default, "\";`\\") {
+			return fmt.Errorf("%s: field %q: default %q must contain no double quotes, semicolons, backquotes or backslashes",
+				owner, field.Name, field.Default)
+		}
+		if err := field.checkRules(); err != nil {
+			return fmt.Errorf("%s: field %q: %w", owner, field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ruleTypes lists the field types each rule applies to. min and max bound
+// the value of numbers and the length of everything else.
+var ruleTypes = map[string][]string{
+	"min":   {"string", "text", "int", "int64", "float", "decimal", "json", "bytes"},
+	"max":   {"string", "text", "int", "int64", "float", "decimal", "json", "bytes"},
+	"email": {"string", "text"},
+	"oneof": {"string", "text", "int", "int64", "uuid"},
+	"regex": {"string", "text", "uuid"},
+}
+
+func (f Field) checkRules() error {
+	r := f.Validate
+	if r == nil {
+		return nil
+	}
+
+	used := map[string]bool{
+		"min":   r.Min != nil,
+		"max":   r.Max != nil,
+		"email": r.Email,
+		"oneof": len(r.OneOf) > 0,
+		"regex": r.Regex != "",
+	}
+	for _, rule := range []string{"min", "max", "email", "oneof", "regex"} {
+		if used[rule] && !contains(ruleTypes[rule], f.Type) {
+			return fmt.Errorf("rule %s does not apply to type %s", rule, f.Type)
+		}
+	}
+
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("min %v is greater than max %v", *r.Min, *r.Max)
+	}
+	for _, value := range r.OneOf {
+		if value == "" || strings.ContainsAny(value, " ,|") {
+			return fmt.Errorf("oneof value %q must be non-empty and contain no spaces, commas or pipes", value)
+		}
+	}
+	if r.Regex != "" {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
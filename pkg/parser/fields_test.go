@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_CheckFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []Field
+		wantErr string
+	}{
+		{name: "valid", fields: []Field{{Name: "email", Type: "string"}, {Name: "born_at", Type: "time"}}},
+		{name: "unknown type", fields: []Field{{Name: "age", Type: "integer"}},
+			wantErr: `entity "user": field "age" has unknown type "integer" (expected one of bool, bytes, decimal, float, int, int64, json, string, text, time, uuid)`},
+		{name: "missing type", fields: []Field{{Name: "age"}},
+			wantErr: `entity "user": field "age" has no type (expected one of bool, bytes, decimal, float, int, int64, json, string, text, time, uuid)`},
+		{name: "reserved", fields: []Field{{Name: "id", Type: "int"}},
+			wantErr: `entity "user": field "id" clashes with a built-in model field`},
+		{name: "duplicate", fields: []Field{{Name: "firstName", Type: "string"}, {Name: "first_name", Type: "string"}},
+			wantErr: `entity "user": field "first_name" clashes with field "firstName"`},
+		{name: "invalid name", fields: []Field{{Name: "1st", Type: "string"}},
+			wantErr: `entity "user": field "1st" is not a valid identifier`},
+		{name: "valid rules", fields: []Field{
+			{Name: "email", Type: "string", Validate: &Rules{Required: true, Email: true, Max: ptr(120)}},
+			{Name: "age", Type: "int", Validate: &Rules{Min: ptr(18), OneOf: []string{"18", "21"}}},
+			{Name: "code", Type: "string", Validate: &Rules{Regex: `^[A-Z]{3}$`}},
+		}},
+		{name: "rule for wrong type", fields: []Field{{Name: "born_at", Type: "time", Validate: &Rules{Email: true}}},
+			wantErr: `entity "user": field "born_at": rule email does not apply to type time`},
+		{name: "min above max", fields: []Field{{Name: "age", Type: "int", Validate: &Rules{Min: ptr(5), Max: ptr(1)}}},
+			wantErr: `entity "user": field "age": min 5 is greater than max 1`},
+		{name: "oneof with space", fields: []Field{{Name: "status", Type: "string", Validate: &Rules{OneOf: []string{"on hold"}}}},
+			wantErr: `entity "user": field "status": oneof value "on hold" must be non-empty and contain no spaces, commas or pipes`},
+		{name: "default with semicolon", fields: []Field{{Name: "status", Type: "string", Default: "a;b"}},
+			wantErr: `entity "user": field "status": default "a;b" must contain no double quotes, semicolons, backquotes or backslashes`},
+		{name: "default with single quote", fields: []Field{{Name: "name", Type: "string", Default: "O'Brien"}}},
+		{name: "bad regex", fields: []Field{{Name: "code", Type: "string", Validate: &Rules{Regex: `[a-`}}},
+			wantErr: "entity \"user\": field \"code\": invalid regex: error parsing regexp: missing closing ]: `[a-`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Entity{Name: "user", Fields: tt.fields}.CheckFields()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
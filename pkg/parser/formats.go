@@ -0,0 +1,596 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec file formats. They all decode into the same Config: JSON and TOML
+// files are turned into the node tree a YAML file parses to, so they are
+// validated alike and issues keep their positions.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+// Formats lists the spec formats, sorted.
+var Formats = []string{FormatJSON, FormatTOML, FormatYAML}
+
+// FormatOf returns the format of a spec file from its extension. Unknown
+// extensions are read as YAML.
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatYAML
+}
+
+// parseDocument parses a spec file into a YAML node tree. It returns nil
+// for a file without content.
+func parseDocument(data []byte, file string) (*yaml.Node, error) {
+	switch FormatOf(file) {
+	case FormatJSON:
+		return parseJSON(data, file)
+	case FormatTOML:
+		return parseTOML(data, file)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	return root.Content[0], nil
+}
+
+// parseJSON reads a JSON document token by token, recording where each
+// value starts.
+func parseJSON(data []byte, file string) (*yaml.Node, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	fail := func(offset int64, err error) error {
+		line, col := position(data, int(offset))
+		return &ValidationError{File: file, Issues: []Issue{{Line: line, Column: col, Message: err.Error()}}}
+	}
+
+	var value func() (*yaml.Node, error)
+	value = func() (*yaml.Node, error) {
+		start := skipJSONSpace(data, int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) {
+				return nil, fail(syntax.Offset, err)
+			}
+			return nil, fail(int64(start), err)
+		}
+		line, col := position(data, start)
+		node := &yaml.Node{Line: line, Column: col}
+
+		switch tok := tok.(type) {
+		case json.Delim:
+			if tok == '{' {
+				node.Kind, node.Tag = yaml.MappingNode, "!!map"
+				for dec.More() {
+					key, err := value()
+					if err != nil {
+						return nil, err
+					}
+					val, err := value()
+					if err != nil {
+						return nil, err
+					}
+					node.Content = append(node.Content, key, val)
+				}
+			} else {
+				node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+				for dec.More() {
+					item, err := value()
+					if err != nil {
+						return nil, err
+					}
+					node.Content = append(node.Content, item)
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, fail(dec.InputOffset(), err)
+			}
+		case string:
+			node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", tok
+		case json.Number:
+			node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!float", tok.String()
+			if _, err := tok.Int64(); err == nil {
+				node.Tag = "!!int"
+			}
+		case bool:
+			node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", strconv.FormatBool(tok)
+		case nil:
+			node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+		}
+		return node, nil
+	}
+
+	doc, err := value()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fail(dec.InputOffset(), errors.New("unexpected data after the top-level value"))
+	}
+	return doc, nil
+}
+
+func skipJSONSpace(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// position converts a byte offset into a line and column, both from 1.
+func position(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	lead := data[:offset]
+	return bytes.Count(lead, []byte{'\n'}) + 1, offset - bytes.LastIndexByte(lead, '\n')
+}
+
+// parseTOML checks a TOML document with the TOML decoder, then builds the
+// node tree from its syntax tree, which keeps the order and positions.
+func parseTOML(data []byte, file string) (*yaml.Node, error) {
+	var check map[string]any
+	if err := toml.Unmarshal(data, &check); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, col := decodeErr.Position()
+			return nil, &ValidationError{File: file, Issues: []Issue{{Line: line, Column: col, Message: decodeErr.Error()}}}
+		}
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	p := &unstable.Parser{}
+	p.Reset(data)
+	at := func(n *unstable.Node, into *yaml.Node) *yaml.Node {
+		if n.Raw.Length > 0 {
+			start := p.Shape(n.Raw).Start
+			into.Line, into.Column = start.Line, start.Column
+		}
+		return into
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	// child returns the mapping under key in m, creating it if needed. A
+	// key holding an array of tables stands for its last table.
+	child := func(m *yaml.Node, key *unstable.Node) *yaml.Node {
+		name := string(key.Data)
+		if i := indexOfKey(m, name); i >= 0 {
+			v := m.Content[i+1]
+			if v.Kind == yaml.SequenceNode && len(v.Content) > 0 {
+				return v.Content[len(v.Content)-1]
+			}
+			return v
+		}
+		keyNode := at(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name})
+		v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: keyNode.Line, Column: keyNode.Column}
+		m.Content = append(m.Content, keyNode, v)
+		return v
+	}
+	// set stores value under a possibly dotted key in m.
+	var convert func(n *unstable.Node, parent *yaml.Node) *yaml.Node
+	set := func(m *yaml.Node, kv *unstable.Node) {
+		keys := kv.Key()
+		keys.Next()
+		for !keys.IsLast() {
+			m = child(m, keys.Node())
+			keys.Next()
+		}
+		key := at(keys.Node(), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(keys.Node().Data)})
+		m.Content = append(m.Content, key, convert(kv.Value(), key))
+	}
+	// convert turns a value into a node. Arrays and inline tables carry no
+	// position of their own and take the one of parent.
+	convert = func(n *unstable.Node, parent *yaml.Node) *yaml.Node {
+		node := at(n, &yaml.Node{Kind: yaml.ScalarNode, Line: parent.Line, Column: parent.Column})
+		switch n.Kind {
+		case unstable.Array:
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for items := n.Children(); items.Next(); {
+				node.Content = append(node.Content, convert(items.Node(), node))
+			}
+		case unstable.InlineTable:
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for kvs := n.Children(); kvs.Next(); {
+				set(node, kvs.Node())
+			}
+		case unstable.String:
+			node.Tag, node.Value = "!!str", string(n.Data)
+		case unstable.Bool:
+			node.Tag, node.Value = "!!bool", string(n.Data)
+		case unstable.Integer:
+			i, _ := strconv.ParseInt(string(n.Data), 0, 64)
+			node.Tag, node.Value = "!!int", strconv.FormatInt(i, 10)
+		case unstable.Float:
+			node.Tag, node.Value = "!!float", tomlFloat(string(n.Data))
+		case unstable.DateTime:
+			node.Tag, node.Value = "!!timestamp", string(n.Data)
+		default:
+			// Local dates and times have no zone; they are kept as text.
+			node.Tag, node.Value = "!!str", string(n.Data)
+		}
+		return node
+	}
+
+	current := root
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.KeyValue:
+			set(current, expr)
+		case unstable.Table:
+			current = root
+			for keys := expr.Key(); keys.Next(); {
+				current = child(current, keys.Node())
+			}
+		case unstable.ArrayTable:
+			m := root
+			keys := expr.Key()
+			keys.Next()
+			for !keys.IsLast() {
+				m = child(m, keys.Node())
+				keys.Next()
+			}
+			key := keys.Node()
+			var list *yaml.Node
+			if i := indexOfKey(m, string(key.Data)); i >= 0 {
+				list = m.Content[i+1]
+			} else {
+				keyNode := at(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(key.Data)})
+				list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: keyNode.Line, Column: keyNode.Column}
+				m.Content = append(m.Content, keyNode, list)
+			}
+			current = at(key, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+			list.Content = append(list.Content, current)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return root, nil
+}
+
+// tomlFloat rewrites a TOML float in the YAML syntax.
+func tomlFloat(s string) string {
+	switch strings.TrimPrefix(s, "+") {
+	case "inf":
+		return ".inf"
+	case "-inf":
+		return "-.inf"
+	case "nan", "-nan":
+		return ".nan"
+	}
+	f, _ := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Encode writes a spec in format. YAML is the canonical form; the JSON and
+// TOML ones are derived from it and decode to the same Config.
+func Encode(config *Config, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatYAML:
+		return buf.Bytes(), nil
+	case FormatJSON, FormatTOML:
+	default:
+		return nil, fmt.Errorf("unknown spec format %q (expected one of %s)", format, strings.Join(Formats, ", "))
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &root); err != nil {
+		return nil, err
+	}
+	doc := root.Content[0]
+
+	out := &bytes.Buffer{}
+	if format == FormatJSON {
+		if err := writeJSON(out, doc, ""); err != nil {
+			return nil, err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, out.Bytes(), "", "  "); err != nil {
+			return nil, err
+		}
+		indented.WriteByte('\n')
+		return indented.Bytes(), nil
+	}
+
+	if err := writeTOMLTable(out, doc, nil, ""); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeJSON writes n as compact JSON, keeping the order of keys. where
+// locates n in error messages.
+func writeJSON(w *bytes.Buffer, n *yaml.Node, where string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		w.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			key, _ := json.Marshal(n.Content[i].Value)
+			w.Write(key)
+			w.WriteByte(':')
+			if err := writeJSON(w, n.Content[i+1], join(where, n.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+	case yaml.SequenceNode:
+		w.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeJSON(w, item, fmt.Sprintf("%s[%d]", where, i)); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	default:
+		switch n.ShortTag() {
+		case "!!null":
+			w.WriteString("null")
+		case "!!bool":
+			w.WriteString(n.Value)
+		case "!!int":
+			i, err := strconv.ParseInt(n.Value, 0, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+			w.WriteString(strconv.FormatInt(i, 10))
+		case "!!float":
+			f, err := yamlFloat(n.Value)
+			if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+				return fmt.Errorf("%s: %s cannot be represented in JSON", where, n.Value)
+			}
+			b, _ := json.Marshal(f)
+			w.Write(b)
+		default:
+			b, _ := json.Marshal(n.Value)
+			w.Write(b)
+		}
+	}
+	return nil
+}
+
+func yamlFloat(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case ".inf", "+.inf":
+		return math.Inf(1), nil
+	case "-.inf":
+		return math.Inf(-1), nil
+	case ".nan":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+}
+
+// writeTOMLTable writes mapping n as the table at path: plain values
+// first, then sub-tables, then arrays of tables. where locates n in error
+// messages.
+func writeTOMLTable(w *bytes.Buffer, n *yaml.Node, path []string, where string) error {
+	var tables, arrays []int
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i].Value, n.Content[i+1]
+		switch {
+		case value.Kind == yaml.MappingNode:
+			tables = append(tables, i)
+			continue
+		case isArrayOfTables(value):
+			arrays = append(arrays, i)
+			continue
+		}
+		w.WriteString(tomlKey(key) + " = ")
+		if err := writeTOMLValue(w, value, join(where, key)); err != nil {
+			return err
+		}
+		w.WriteByte('\n')
+	}
+
+	for _, i := range tables {
+		sub := append(append([]string(nil), path...), n.Content[i].Value)
+		if needsHeader(n.Content[i+1]) {
+			fmt.Fprintf(w, "\n[%s]\n", tomlPath(sub))
+		}
+		if err := writeTOMLTable(w, n.Content[i+1], sub, join(where, n.Content[i].Value)); err != nil {
+			return err
+		}
+	}
+	for _, i := range arrays {
+		sub := append(append([]string(nil), path...), n.Content[i].Value)
+		for j, item := range n.Content[i+1].Content {
+			fmt.Fprintf(w, "\n[[%s]]\n", tomlPath(sub))
+			if err := writeTOMLTable(w, item, sub, fmt.Sprintf("%s[%d]", join(where, n.Content[i].Value), j)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// needsHeader reports whether table n needs its [header]: tables holding
+// only other tables are implied by theirs.
+func needsHeader(n *yaml.Node) bool {
+	if len(n.Content) == 0 {
+		return true
+	}
+	for i := 1; i < len(n.Content); i += 2 {
+		if v := n.Content[i]; v.Kind != yaml.MappingNode && !isArrayOfTables(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// isArrayOfTables reports whether n is written as [[tables]]: a non-empty
+// list of mappings.
+func isArrayOfTables(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		return false
+	}
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTOMLValue writes n inline. where locates it in error messages.
+func writeTOMLValue(w *bytes.Buffer, n *yaml.Node, where string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		w.WriteString("{ ")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(tomlKey(n.Content[i].Value) + " = ")
+			if err := writeTOMLValue(w, n.Content[i+1], where+"."+n.Content[i].Value); err != nil {
+				return err
+			}
+		}
+		w.WriteString(" }")
+		return nil
+	case yaml.SequenceNode:
+		w.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			if err := writeTOMLValue(w, item, fmt.Sprintf("%s[%d]", where, i)); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+		return nil
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		return fmt.Errorf("%s: null cannot be represented in TOML", where)
+	case "!!bool", "!!timestamp":
+		w.WriteString(n.Value)
+	case "!!int":
+		i, err := strconv.ParseInt(n.Value, 0, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		w.WriteString(strconv.FormatInt(i, 10))
+	case "!!float":
+		f, err := yamlFloat(n.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		switch {
+		case math.IsNaN(f):
+			w.WriteString("nan")
+		case math.IsInf(f, 1):
+			w.WriteString("inf")
+		case math.IsInf(f, -1):
+			w.WriteString("-inf")
+		default:
+			s := strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(s, ".e") {
+				s += ".0"
+			}
+			w.WriteString(s)
+		}
+	default:
+		w.WriteString(tomlString(n.Value))
+	}
+	return nil
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
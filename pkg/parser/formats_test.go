@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const formatsSpec = `project:
+  name: shop
+  type: rest
+  router: chi
+  port: 9000
+  db: postgres
+databases:
+  analytics: { db: sqlite }
+features:
+  cache: redis
+profiles:
+  - dev
+  - name: prod
+    env: { LOG_LEVEL: warn }
+entities:
+  - name: user
+    fields:
+      - { name: email, type: string, unique: true, validate: { email: true, max: 120 } }
+      - { name: score, type: float, default: "0.5" }
+    relations:
+      - { type: has_many, entity: product }
+    seeds:
+      - { id: 1, email: "ada@example.com", score: 2.5, joined: 2024-01-02T10:00:00Z, active: true }
+  - name: event
+    store: analytics
+    fields:
+      - { name: kind, type: string }
+  - product
+custom_logic:
+  user:
+    - { name: by_email, method: GET, path: "/by-email/{email}", query: [limit] }
+`
+
+func TestEncode_RoundTrip(t *testing.T) {
+	want, err := DecodeYAML([]byte(formatsSpec), "project.yaml")
+	require.NoError(t, err)
+
+	for _, format := range []string{FormatJSON, FormatTOML, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			data, err := Encode(want, format)
+			require.NoError(t, err)
+
+			got, err := DecodeYAML(data, "project."+format)
+			require.NoError(t, err, string(data))
+			if format == FormatJSON {
+				// JSON has no timestamps; seed times come back as text.
+				assert.Equal(t, "2024-01-02T10:00:00Z", got.Entities[0].Seeds.Rows[0]["joined"])
+				got.Entities[0].Seeds.Rows[0]["joined"] = want.Entities[0].Seeds.Rows[0]["joined"]
+			}
+			assert.Equal(t, want, got)
+
+			again, err := Encode(got, format)
+			require.NoError(t, err)
+			assert.Equal(t, string(data), string(again), "encoding is stable")
+		})
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	config, err := DecodeYAML([]byte("project: { name: shop }\nentities:\n  - name: user\n    seeds:\n      - { id: 1, email: ~ }\n"), "project.yaml")
+	require.NoError(t, err)
+
+	_, err = Encode(config, FormatTOML)
+	assert.EqualError(t, err, "entities[0].seeds[0].email: null cannot be represented in TOML")
+
+	_, err = Encode(config, "xml")
+	assert.EqualError(t, err, `unknown spec format "xml" (expected one of json, toml, yaml)`)
+}
+
+func TestReadYAML_Formats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{name: "json", file: "project.json", content: "{\n  \"project\": {\n    \"name\": \"shop\",\n    \"router\": \"gim\"\n  }\n}\n",
+			wantErr: `project.json:4:15: unknown router "gim"`},
+		{name: "json unknown key", file: "project.json", content: "{\"project\": {\"name\": \"shop\", \"prot\": 80}}",
+			wantErr: `project.json:1:30: unknown field "prot" in project`},
+		{name: "json syntax", file: "project.json", content: "{\"project\": }",
+			wantErr: "project.json:1:"},
+		{name: "toml", file: "project.toml", content: "[project]\nname = \"shop\"\nport = 70000\n",
+			wantErr: "project.toml:3:8: port 70000 is out of range (1-65535)"},
+		{name: "toml wrong type", file: "project.toml", content: "[project]\nname = \"shop\"\nport = [1]\n",
+			wantErr: "project.toml:3: cannot unmarshal !!seq into int"},
+		{name: "toml syntax", file: "project.toml", content: "[project\n",
+			wantErr: "project.toml:1:9: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeSpecs(t, map[string]string{tt.file: tt.content})
+			_, err := ReadYAML(filepath.Join(dir, tt.file))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestReadYAML_ExtendsAcrossFormats(t *testing.T) {
+	dir := writeSpecs(t, map[string]string{
+		"base.toml":    "[project]\nrouter = \"echo\"\nport = 9000\n",
+		"project.json": `{"extends": "base.toml", "project": {"name": "shop"}, "entities": ["user"]}`,
+	})
+
+	config, err := ReadYAML(filepath.Join(dir, "project.json"))
+	require.NoError(t, err)
+	assert.Equal(t, Project{Name: "shop", Router: "echo", Port: 9000}, config.Project)
+
+	resolved, err := ResolveYAML(filepath.Join(dir, "project.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "project:\n  router: echo\n  port: 9000\n  name: shop\nentities:\n  - user\n", string(resolved))
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Job is one kind of job of a worker project. It may be written either as
+// a plain name (`- send_email`) or as an object with a typed payload and
+// how the worker runs it.
+type Job struct {
+	Name    string  `yaml:"name"`
+	Payload []Field `yaml:"payload,omitempty"`
+	// MaxAttempts counts the first run; a job failing that many times is
+	// dead-lettered. Timeout bounds each attempt, and Concurrency the jobs
+	// of this kind run at once (0 leaves only the worker's own limit).
+	MaxAttempts int    `yaml:"max_attempts,omitempty"`
+	Timeout     string `yaml:"timeout,omitempty"`
+	Concurrency int    `yaml:"concurrency,omitempty"`
+}
+
+func (j *Job) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		j.Name = node.Value
+		return nil
+	}
+
+	type plain Job
+	return node.Decode((*plain)(j))
+}
+
+func (j Job) MarshalYAML() (interface{}, error) {
+	if len(j.Payload) == 0 && j.MaxAttempts == 0 && j.Timeout == "" && j.Concurrency == 0 {
+		return j.Name, nil
+	}
+
+	type plain Job
+	return plain(j), nil
+}
+
+var jobName = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// reservedJobs would clash with the functions of the generated jobs
+// package.
+var reservedJobs = []string{"register", "enqueue"}
+
+// checkJobs reports jobs that cannot be generated: invalid or duplicate
+// names, bad payload fields and limits that are out of range.
+func (c *Config) checkJobs(doc *yaml.Node) []Issue {
+	var issues []Issue
+	add := func(node *yaml.Node, format string, args ...any) {
+		issues = append(issues, nodeIssue(node, fmt.Sprintf(format, args...)))
+	}
+
+	names := map[string]bool{}
+	for i, job := range c.Jobs {
+		switch {
+		case job.Name == "":
+			add(lookup(doc, "jobs", i), "job without a name")
+			continue
+		case !jobName.MatchString(job.Name):
+			add(lookup(doc, "jobs", i, "name"), "job name %q must be lowercase words separated by underscores", job.Name)
+		case contains(reservedJobs, job.Name):
+			add(lookup(doc, "jobs", i, "name"), "job name %q is reserved", job.Name)
+		case names[job.Name]:
+			add(lookup(doc, "jobs", i, "name"), "job %q is declared twice", job.Name)
+		}
+		names[job.Name] = true
+
+		if err := checkFields(fmt.Sprintf("job %q", job.Name), job.Payload, nil); err != nil {
+			add(lookup(doc, "jobs", i, "payload"), "%v", err)
+		}
+		if job.MaxAttempts < 0 {
+			add(lookup(doc, "jobs", i, "max_attempts"), "job %q: max_attempts must be at least 1", job.Name)
+		}
+		if job.Timeout != "" {
+			if d, err := time.ParseDuration(job.Timeout); err != nil || d <= 0 {
+				add(lookup(doc, "jobs", i, "timeout"), "job %q: timeout %q is not a positive duration", job.Name, job.Timeout)
+			}
+		}
+		if job.Concurrency < 0 {
+			add(lookup(doc, "jobs", i, "concurrency"), "job %q: concurrency must not be negative", job.Name)
+		}
+	}
+	return issues
+}
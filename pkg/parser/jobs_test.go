@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_CheckJobs(t *testing.T) {
+	const project = "project:\n  type: worker\njobs:\n"
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "valid", spec: `
+  - resize_image
+  - name: send_email
+    payload:
+      - { name: to, type: string, validate: { required: true, email: true } }
+    max_attempts: 3
+    timeout: 30s
+    concurrency: 2
+`},
+		{name: "invalid name", spec: "  - send-email\n",
+			wantErr: `p.yaml:4:5: job name "send-email" must be lowercase words separated by underscores`},
+		{name: "reserved name", spec: "  - enqueue\n",
+			wantErr: `p.yaml:4:5: job name "enqueue" is reserved`},
+		{name: "duplicate", spec: "  - ping\n  - name: ping\n",
+			wantErr: `p.yaml:5:11: job "ping" is declared twice`},
+		{name: "bad payload", spec: "  - name: ping\n    payload: [{ name: at, type: date }]\n",
+			wantErr: `p.yaml:5:14: job "ping": field "at" has unknown type "date" (expected one of bool, bytes, decimal, float, int, int64, json, string, text, time, uuid)`},
+		{name: "negative attempts", spec: "  - name: ping\n    max_attempts: -1\n",
+			wantErr: `p.yaml:5:19: job "ping": max_attempts must be at least 1`},
+		{name: "bad timeout", spec: "  - name: ping\n    timeout: -5s\n",
+			wantErr: `p.yaml:5:14: job "ping": timeout "-5s" is not a positive duration`},
+		{name: "negative concurrency", spec: "  - name: ping\n    concurrency: -2\n",
+			wantErr: `p.yaml:5:18: job "ping": concurrency must not be negative`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := DecodeYAML([]byte(project+tt.spec), "p.yaml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Len(t, config.Jobs, 2)
+				assert.Equal(t, "resize_image", config.Jobs[0].Name)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModularArch is the architecture that generates modules. Specs that
+// declare modules default to it.
+const ModularArch = "modular"
+
+// DefaultModule holds every entity of modular projects that declare no
+// modules.
+const DefaultModule = "core"
+
+var moduleName = regexp.MustCompile(`^[a-z][a-z0-9]+$`)
+
+// reservedModules would clash with the packages the generated routes
+// import next to the modules.
+var reservedModules = []string{"modules", "http", "json", "gin", "chi", "echo", "fiber", "adaptor", "mux"}
+
+// ModuleOf returns the module entity belongs to, or "" when it is in none.
+func (c *Config) ModuleOf(entity string) string {
+	for _, module := range c.Modules {
+		for _, name := range module.Entities {
+			if strings.EqualFold(name, entity) {
+				return module.Name
+			}
+		}
+	}
+	return ""
+}
+
+// checkModules reports modules that cannot be generated: invalid or
+// duplicate names, unknown entities, entities in no module or in two, and
+// relations between the entities of different modules, which would make
+// one module depend on the internals of another.
+func (c *Config) checkModules(doc *yaml.Node) []Issue {
+	if len(c.Modules) == 0 {
+		return nil
+	}
+
+	var issues []Issue
+	add := func(node *yaml.Node, format string, args ...any) {
+		issues = append(issues, nodeIssue(node, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Project.Arch != "" && c.Project.Arch != ModularArch {
+		add(lookup(doc, "project", "arch"), "modules need arch %q, not %q", ModularArch, c.Project.Arch)
+	}
+
+	names := map[string]bool{}
+	owner := map[string]string{}
+	for i, module := range c.Modules {
+		switch {
+		case module.Name == "":
+			add(lookup(doc, "modules", i), "module without a name")
+		case !moduleName.MatchString(module.Name) || token.IsKeyword(module.Name):
+			add(lookup(doc, "modules", i, "name"), "module name %q must be two or more lowercase letters and digits, starting with a letter", module.Name)
+		case contains(reservedModules, module.Name):
+			add(lookup(doc, "modules", i, "name"), "module name %q is reserved", module.Name)
+		case names[module.Name]:
+			add(lookup(doc, "modules", i, "name"), "module %q is declared twice", module.Name)
+		}
+		names[module.Name] = true
+
+		if len(module.Entities) == 0 {
+			add(lookup(doc, "modules", i), "module %q has no entities", module.Name)
+		}
+		for j, name := range module.Entities {
+			entity, ok := c.Entity(name)
+			key := strings.ToLower(entity.Name)
+			switch {
+			case !ok:
+				add(lookup(doc, "modules", i, "entities", j), "module %q: unknown entity %q", module.Name, name)
+			case owner[key] != "":
+				add(lookup(doc, "modules", i, "entities", j), "entity %q is in modules %q and %q", entity.Name, owner[key], module.Name)
+			default:
+				owner[key] = module.Name
+			}
+		}
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+
+	for i, entity := range c.Entities {
+		module := owner[strings.ToLower(entity.Name)]
+		if module == "" {
+			add(lookup(doc, "entities", i), "entity %q is not in any module", entity.Name)
+			continue
+		}
+		for j, rel := range entity.Relations {
+			if other := c.ModuleOf(rel.Entity); other != "" && other != module {
+				add(lookup(doc, "entities", i, "relations", j),
+					"entity %q (module %q) cannot relate to %q (module %q); keep related entities in one module, or refer to it by a plain field",
+					entity.Name, module, rel.Entity, other)
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_CheckModules(t *testing.T) {
+	const entities = `
+entities:
+  - user
+  - name: order
+    relations: [{ type: belongs_to, entity: user }]
+  - product
+`
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "valid", spec: `
+modules:
+  - { name: sales, entities: [user, Order] }
+  - { name: catalog, entities: [product] }
+` + entities},
+		{name: "other arch", spec: "project:\n  arch: clean\nmodules:\n  - { name: all, entities: [user] }\nentities: [user]\n",
+			wantErr: `p.yaml:2:9: modules need arch "modular", not "clean"`},
+		{name: "invalid name", spec: "modules:\n  - { name: Sales, entities: [user] }\nentities: [user]\n",
+			wantErr: `p.yaml:2:13: module name "Sales" must be two or more lowercase letters and digits, starting with a letter`},
+		{name: "reserved name", spec: "modules:\n  - { name: http, entities: [user] }\nentities: [user]\n",
+			wantErr: `p.yaml:2:13: module name "http" is reserved`},
+		{name: "unknown entity", spec: "modules:\n  - { name: sales, entities: [user, invoice] }\nentities: [user]\n",
+			wantErr: `p.yaml:2:37: module "sales": unknown entity "invoice"`},
+		{name: "entity in two modules", spec: "modules:\n  - { name: sales, entities: [user] }\n  - { name: crm, entities: [User] }\nentities: [user]\n",
+			wantErr: `p.yaml:3:29: entity "user" is in modules "sales" and "crm"`},
+		{name: "entity in no module", spec: "modules:\n  - { name: sales, entities: [user, order] }\n" + entities,
+			wantErr: `p.yaml:8:5: entity "product" is not in any module`},
+		{name: "relation across modules", spec: "modules:\n  - { name: sales, entities: [order] }\n  - { name: crm, entities: [user, product] }\n" + entities,
+			wantErr: `p.yaml:8:17: entity "order" (module "sales") cannot relate to "user" (module "crm"); keep related entities in one module, or refer to it by a plain field`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := DecodeYAML([]byte(tt.spec), "p.yaml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "sales", config.ModuleOf("order"))
+				assert.Equal(t, "catalog", config.ModuleOf("Product"))
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+)
+
+// Operation is an extra endpoint of an entity, declared under custom_logic
+// next to the generated CRUD ones.
+type Operation struct {
+	Name   string   `yaml:"name"`
+	Method string   `yaml:"method,omitempty"`
+	Path   string   `yaml:"path,omitempty"`
+	Query  []string `yaml:"query,omitempty"`
+}
+
+// HTTPMethods are the methods an operation may use.
+var HTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// reservedOperations would clash with the generated CRUD methods.
+var reservedOperations = []string{"get", "create", "update", "delete"}
+
+var pathParam = regexp.MustCompile(`\{([^}]*)\}`)
+
+// HTTPMethod is the method of the operation, POST unless set.
+func (o Operation) HTTPMethod() string {
+	if o.Method == "" {
+		return "POST"
+	}
+	return strings.ToUpper(o.Method)
+}
+
+// RelativePath is the path of the operation below the entity's resource,
+// /{id}/<name> unless set.
+func (o Operation) RelativePath() string {
+	if o.Path == "" {
+		return "/{id}/" + strings.ReplaceAll(naming.Snake(o.Name), "_", "-")
+	}
+	return o.Path
+}
+
+// PathParams are the names of the {param} segments of the path, in order.
+func (o Operation) PathParams() []string {
+	var params []string
+	for _, m := range pathParam.FindAllStringSubmatch(o.RelativePath(), -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+// CheckCustomLogic reports the first problem in the custom_logic section:
+// unknown entities, invalid names, methods or paths, and operations that
+// clash with each other or with the generated CRUD routes.
+func (c *Config) CheckCustomLogic() error {
+	entities := make([]string, 0, len(c.CustomLogic))
+	for entity := range c.CustomLogic {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	for _, entity := range entities {
+		if err := c.checkOperations(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) checkOperations(entity string) error {
+	if _, ok := c.Entity(entity); !ok {
+		return fmt.Errorf("custom_logic: unknown entity %q", entity)
+	}
+
+	names := map[string]bool{}
+	routes := map[string]string{
+		"GET /": "the list route", "POST /": "the create route",
+		"GET /{}": "the get route", "PUT /{}": "the update route", "DELETE /{}": "the delete route",
+	}
+	for _, op := range c.CustomLogic[entity] {
+		if err := op.check(); err != nil {
+			return fmt.Errorf("custom_logic: entity %q: %w", entity, err)
+		}
+
+		name := naming.Snake(op.Name)
+		if names[name] {
+			return fmt.Errorf("custom_logic: entity %q: operation %q is declared twice", entity, op.Name)
+		}
+		names[name] = true
+
+		route := op.HTTPMethod() + " " + pathParam.ReplaceAllString(strings.TrimSuffix(op.RelativePath(), "/"), "{}")
+		if route == op.HTTPMethod()+" " {
+			route += "/"
+		}
+		if other, ok := routes[route]; ok {
+			return fmt.Errorf("custom_logic: entity %q: operation %q has the same route as %s", entity, op.Name, other)
+		}
+		routes[route] = fmt.Sprintf("operation %q", op.Name)
+	}
+	return nil
+}
+
+func (o Operation) check() error {
+	switch {
+	case o.Name == "":
+		return fmt.Errorf("operation without a name")
+	case !token.IsIdentifier(naming.Pascal(o.Name)):
+		return fmt.Errorf("operation %q is not a valid identifier", o.Name)
+	}
+	for _, reserved := range reservedOperations {
+		if naming.Snake(o.Name) == reserved {
+			return fmt.Errorf("operation %q clashes with a generated CRUD method", o.Name)
+		}
+	}
+
+	if !contains(HTTPMethods, o.HTTPMethod()) {
+		return fmt.Errorf("operation %q: unknown method %q (expected one of %s)", o.Name, o.Method, strings.Join(HTTPMethods, ", "))
+	}
+
+	path := o.RelativePath()
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("operation %q: path %q must start with /", o.Name, path)
+	}
+	if strings.ContainsAny(pathParam.ReplaceAllString(path, ""), "{}?") {
+		return fmt.Errorf("operation %q: path %q is malformed; declare query parameters under query", o.Name, path)
+	}
+
+	seen := map[string]bool{}
+	for _, param := range append(o.PathParams(), o.Query...) {
+		switch {
+		case !token.IsIdentifier(naming.Camel(param)):
+			return fmt.Errorf("operation %q: parameter %q is not a valid identifier", o.Name, param)
+		case seen[naming.Camel(param)]:
+			return fmt.Errorf("operation %q: parameter %q is declared twice", o.Name, param)
+		}
+		seen[naming.Camel(param)] = true
+	}
+	return nil
+}
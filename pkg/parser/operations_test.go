@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperation_Defaults(t *testing.T) {
+	op := Operation{Name: "markPaid"}
+	assert.Equal(t, "POST", op.HTTPMethod())
+	assert.Equal(t, "/{id}/mark-paid", op.RelativePath())
+	assert.Equal(t, []string{"id"}, op.PathParams())
+
+	op = Operation{Name: "search", Method: "get", Path: "/search", Query: []string{"q"}}
+	assert.Equal(t, "GET", op.HTTPMethod())
+	assert.Empty(t, op.PathParams())
+}
+
+func TestConfig_CheckCustomLogic(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     map[string][]Operation
+		wantErr string
+	}{
+		{name: "valid", ops: map[string][]Operation{
+			"user":    {{Name: "activate"}, {Name: "by_email", Method: "GET", Path: "/by-email/{email}"}},
+			"product": {{Name: "search", Method: "GET", Path: "/search", Query: []string{"q"}}},
+		}},
+		{name: "unknown entity", ops: map[string][]Operation{"order": {{Name: "pay"}}},
+			wantErr: `custom_logic: unknown entity "order"`},
+		{name: "reserved name", ops: map[string][]Operation{"user": {{Name: "delete"}}},
+			wantErr: `custom_logic: entity "user": operation "delete" clashes with a generated CRUD method`},
+		{name: "unknown method", ops: map[string][]Operation{"user": {{Name: "ping", Method: "FETCH"}}},
+			wantErr: `custom_logic: entity "user": operation "ping": unknown method "FETCH" (expected one of GET, POST, PUT, PATCH, DELETE)`},
+		{name: "query in path", ops: map[string][]Operation{"user": {{Name: "find", Method: "GET", Path: "/find?q="}}},
+			wantErr: `custom_logic: entity "user": operation "find": path "/find?q=" is malformed; declare query parameters under query`},
+		{name: "clashes with crud route", ops: map[string][]Operation{"user": {{Name: "show", Method: "GET", Path: "/{key}"}}},
+			wantErr: `custom_logic: entity "user": operation "show" has the same route as the get route`},
+		{name: "duplicate", ops: map[string][]Operation{"user": {{Name: "activate"}, {Name: "Activate", Path: "/{id}/on"}}},
+			wantErr: `custom_logic: entity "user": operation "Activate" is declared twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Entities: []Entity{{Name: "user"}, {Name: "product"}}, CustomLogic: tt.ops}
+			err := c.CheckCustomLogic()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
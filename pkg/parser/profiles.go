@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// LocalProfile is the profile of .env, used when APP_ENV is not set. It
+// cannot be declared.
+const LocalProfile = "local"
+
+var (
+	profileName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	envKey      = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+)
+
+// check reports a profile whose name or env keys cannot be used.
+func (p Profile) check() error {
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("profile without a name")
+	case p.Name == LocalProfile:
+		return fmt.Errorf("profile %q is reserved for .env", p.Name)
+	case !profileName.MatchString(p.Name):
+		return fmt.Errorf("profile name %q must be lowercase letters, digits, '-' or '_'", p.Name)
+	}
+	for _, key := range sortedKeys(p.Env) {
+		if !envKey.MatchString(key) {
+			return fmt.Errorf("profile %q: %q is not a valid environment variable name", p.Name, key)
+		}
+		if key == "APP_ENV" {
+			return fmt.Errorf("profile %q: APP_ENV is set by the profile itself", p.Name)
+		}
+	}
+	return nil
+}
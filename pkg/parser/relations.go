@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/naming"
+)
+
+const (
+	BelongsTo  = "belongs_to"
+	HasMany    = "has_many"
+	ManyToMany = "many_to_many"
+)
+
+// ForeignKeyColumn is the column linking the two sides of a belongs_to or
+// has_many relation declared on owner. It lives on owner for belongs_to and
+// on the target for has_many.
+func (r Relation) ForeignKeyColumn(owner string) string {
+	if r.ForeignKey != "" {
+		return r.ForeignKey
+	}
+	switch r.Type {
+	case BelongsTo:
+		return naming.Snake(r.Entity) + "_id"
+	case HasMany:
+		return naming.Snake(owner) + "_id"
+	}
+	return ""
+}
+
+// FieldName is the name of the association on the owner's model: the
+// target for belongs_to (or the foreign key without its _id suffix), and
+// the pluralised target otherwise.
+func (r Relation) FieldName() string {
+	switch {
+	case r.Name != "":
+		return naming.Pascal(r.Name)
+	case r.Type == BelongsTo && r.ForeignKey != "" && strings.HasSuffix(strings.ToLower(r.ForeignKey), "_id"):
+		return naming.Pascal(r.ForeignKey[:len(r.ForeignKey)-3])
+	case r.Type == BelongsTo:
+		return naming.Pascal(r.Entity)
+	}
+	return naming.Plural(naming.Pascal(r.Entity))
+}
+
+// JoinTableName is the table linking the two sides of a many_to_many
+// relation. Both sides derive the same name so that they share the table.
+func (r Relation) JoinTableName(owner string) string {
+	if r.JoinTable != "" {
+		return r.JoinTable
+	}
+	a, b := naming.Snake(owner), naming.Snake(r.Entity)
+	if b < a {
+		a, b = b, a
+	}
+	return a + "_" + naming.Plural(b)
+}
+
+// CheckRelations reports the first referential problem between entities:
+// unknown relation types or targets, foreign keys clashing with fields,
+// associations or foreign keys declared twice on one model, and required
+// belongs_to relations that form a cycle, which would make it impossible to
+// insert the first row.
+func (c *Config) CheckRelations() error {
+	required := map[string][]string{}
+
+	for _, entity := range c.Entities {
+		// The Go fields of the model, and the belongs_to foreign keys it
+		// holds, each with what declares it.
+		names := map[string]string{}
+		for _, field := range entity.Fields {
+			names[naming.Pascal(field.Name)] = fmt.Sprintf("field %q", field.Name)
+		}
+		columns := map[string]string{}
+
+		for _, rel := range entity.Relations {
+			where := fmt.Sprintf("entity %q: relation to %q", entity.Name, rel.Entity)
+
+			target, ok := c.Entity(rel.Entity)
+			if !ok {
+				return fmt.Errorf("%s: unknown entity %q", where, rel.Entity)
+			}
+
+			name := rel.FieldName()
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("%s: association %s clashes with %s; give the relation a distinct name", where, name, other)
+			}
+			names[name] = fmt.Sprintf("the relation to %q", rel.Entity)
+			if rel.Type == BelongsTo {
+				column := naming.Snake(rel.ForeignKeyColumn(entity.Name))
+				if other, ok := columns[column]; ok {
+					return fmt.Errorf("%s: foreign key %s is already used by %s; give the relation a distinct foreign_key", where, column, other)
+				}
+				columns[column] = fmt.Sprintf("the relation to %q", rel.Entity)
+			}
+
+			switch rel.Type {
+			case BelongsTo:
+				if err := checkForeignKey(entity, rel.ForeignKeyColumn(entity.Name)); err != nil {
+					return fmt.Errorf("%s: %w", where, err)
+				}
+				if rel.Required {
+					required[entity.Name] = append(required[entity.Name], target.Name)
+				}
+				continue
+			case HasMany:
+				if err := checkForeignKey(target, rel.ForeignKeyColumn(entity.Name)); err != nil {
+					return fmt.Errorf("%s: %w", where, err)
+				}
+			case ManyToMany:
+				if strings.EqualFold(entity.Name, target.Name) {
+					return fmt.Errorf("%s: many_to_many relations of an entity with itself are not supported", where)
+				}
+			default:
+				return fmt.Errorf("%s: unknown relation type %q (expected one of %s, %s, %s)",
+					where, rel.Type, BelongsTo, HasMany, ManyToMany)
+			}
+
+			if rel.Required {
+				return fmt.Errorf("%s: required only applies to %s relations", where, BelongsTo)
+			}
+		}
+	}
+
+	return checkCycles(required)
+}
+
+func checkForeignKey(holder Entity, column string) error {
+	for _, field := range holder.Fields {
+		if naming.Snake(field.Name) == naming.Snake(column) {
+			return fmt.Errorf("foreign key %s clashes with field %q of %q", column, field.Name, holder.Name)
+		}
+	}
+	return nil
+}
+
+// checkCycles looks for a cycle in the graph of required relations and
+// reports it as a path, e.g. "a -> b -> a".
+func checkCycles(edges map[string][]string) error {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+
+	var visit func(node string) error
+	visit = func(node string) error {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, next := range edges[node] {
+			switch state[next] {
+			case visiting:
+				start := 0
+				for i, n := range stack {
+					if n == next {
+						start = i
+					}
+				}
+				path := append(append([]string(nil), stack[start:]...), next)
+				return fmt.Errorf("required relations form a cycle: %s", strings.Join(path, " -> "))
+			case unvisited:
+				if err := visit(next); err != nil {
+					return err
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = done
+		return nil
+	}
+
+	nodes := make([]string, 0, len(edges))
+	for node := range edges {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			if err := visit(node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_CheckRelations(t *testing.T) {
+	tests := []struct {
+		name     string
+		entities []Entity
+		wantErr  string
+	}{
+		{name: "valid", entities: []Entity{
+			{Name: "user", Relations: []Relation{{Type: HasMany, Entity: "order"}, {Type: ManyToMany, Entity: "role"}}},
+			{Name: "order", Relations: []Relation{{Type: BelongsTo, Entity: "User", Required: true}}},
+			{Name: "role"},
+		}},
+		{name: "unknown target", entities: []Entity{
+			{Name: "order", Relations: []Relation{{Type: BelongsTo, Entity: "customer"}}},
+		}, wantErr: `entity "order": relation to "customer": unknown entity "customer"`},
+		{name: "unknown type", entities: []Entity{
+			{Name: "user", Relations: []Relation{{Type: "has_one", Entity: "user"}}},
+		}, wantErr: `entity "user": relation to "user": unknown relation type "has_one" (expected one of belongs_to, has_many, many_to_many)`},
+		{name: "foreign key clash", entities: []Entity{
+			{Name: "user"},
+			{Name: "order", Fields: []Field{{Name: "user_id", Type: "int"}}, Relations: []Relation{{Type: BelongsTo, Entity: "user"}}},
+		}, wantErr: `entity "order": relation to "user": foreign key user_id clashes with field "user_id" of "order"`},
+		{name: "association declared twice", entities: []Entity{
+			{Name: "customer"},
+			{Name: "order", Relations: []Relation{{Type: BelongsTo, Entity: "customer"}, {Type: BelongsTo, Entity: "customer"}}},
+		}, wantErr: `entity "order": relation to "customer": association Customer clashes with the relation to "customer"; give the relation a distinct name`},
+		{name: "association clashing with field", entities: []Entity{
+			{Name: "customer"},
+			{Name: "order", Fields: []Field{{Name: "customer", Type: "string"}}, Relations: []Relation{{Type: BelongsTo, Entity: "customer"}}},
+		}, wantErr: `entity "order": relation to "customer": association Customer clashes with field "customer"; give the relation a distinct name`},
+		{name: "foreign key declared twice", entities: []Entity{
+			{Name: "customer"},
+			{Name: "order", Relations: []Relation{
+				{Type: BelongsTo, Entity: "customer", Name: "buyer"},
+				{Type: BelongsTo, Entity: "customer", Name: "seller"},
+			}},
+		}, wantErr: `entity "order": relation to "customer": foreign key customer_id is already used by the relation to "customer"; give the relation a distinct foreign_key`},
+		{name: "distinct relations to one entity", entities: []Entity{
+			{Name: "customer"},
+			{Name: "order", Relations: []Relation{
+				{Type: BelongsTo, Entity: "customer", ForeignKey: "buyer_id"},
+				{Type: BelongsTo, Entity: "customer", ForeignKey: "seller_id"},
+			}},
+		}},
+		{name: "required cycle", entities: []Entity{
+			{Name: "a", Relations: []Relation{{Type: BelongsTo, Entity: "b", Required: true}}},
+			{Name: "b", Relations: []Relation{{Type: BelongsTo, Entity: "c", Required: true}}},
+			{Name: "c", Relations: []Relation{{Type: BelongsTo, Entity: "a", Required: true}}},
+		}, wantErr: "required relations form a cycle: a -> b -> c -> a"},
+		{name: "optional cycle", entities: []Entity{
+			{Name: "a", Relations: []Relation{{Type: BelongsTo, Entity: "b", Required: true}}},
+			{Name: "b", Relations: []Relation{{Type: BelongsTo, Entity: "a"}}},
+		}},
+		{name: "required self reference", entities: []Entity{
+			{Name: "category", Relations: []Relation{{Type: BelongsTo, Entity: "category", ForeignKey: "parent_id", Required: true}}},
+		}, wantErr: "required relations form a cycle: category -> category"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Entities: tt.entities}).CheckRelations()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRelation_Names(t *testing.T) {
+	assert.Equal(t, "user_id", Relation{Type: BelongsTo, Entity: "user"}.ForeignKeyColumn("order"))
+	assert.Equal(t, "user_id", Relation{Type: HasMany, Entity: "order"}.ForeignKeyColumn("user"))
+	assert.Equal(t, "Parent", Relation{Type: BelongsTo, Entity: "category", ForeignKey: "parent_id"}.FieldName())
+	assert.Equal(t, "Orders", Relation{Type: HasMany, Entity: "order"}.FieldName())
+	assert.Equal(t, "role_users", Relation{Type: ManyToMany, Entity: "role"}.JoinTableName("user"))
+	assert.Equal(t, "role_users", Relation{Type: ManyToMany, Entity: "user"}.JoinTableName("role"))
+}
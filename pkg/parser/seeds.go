@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Seeds are the fixture rows of an entity, either written inline as a list
+// of objects or as the path of a .csv or .json file.
+type Seeds struct {
+	File string
+	Rows []map[string]any
+}
+
+func (s *Seeds) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		s.File = node.Value
+		return nil
+	case yaml.SequenceNode:
+		return node.Decode(&s.Rows)
+	default:
+		return fmt.Errorf("line %d: seeds must be a list of rows or a file path", node.Line)
+	}
+}
+
+func (s Seeds) MarshalYAML() (interface{}, error) {
+	if s.File != "" {
+		return s.File, nil
+	}
+	return s.Rows, nil
+}
+
+// LoadSeeds returns the seed rows of the entity. Relative file paths are
+// resolved against baseDir, the directory of the project.yaml. CSV values
+// are converted according to the declared field types.
+func LoadSeeds(entity Entity, baseDir string) ([]map[string]any, error) {
+	if entity.Seeds == nil {
+		return nil, nil
+	}
+	if entity.Seeds.File == "" {
+		return entity.Seeds.Rows, nil
+	}
+
+	path := entity.Seeds.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("seeds of %s: %w", entity.Name, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var rows []map[string]any
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("seeds of %s: %s: %w", entity.Name, entity.Seeds.File, err)
+		}
+		return rows, nil
+	case ".csv":
+		rows, err := readCSVSeeds(entity, string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("seeds of %s: %s: %w", entity.Name, entity.Seeds.File, err)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("seeds of %s: %s: only .csv and .json files are supported", entity.Name, entity.Seeds.File)
+	}
+}
+
+func readCSVSeeds(entity Entity, content string) ([]map[string]any, error) {
+	records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	types := map[string]string{"id": "int"}
+	for _, field := range entity.Fields {
+		types[field.Name] = field.Type
+	}
+	for _, rel := range entity.Relations {
+		if rel.ForeignKey != "" {
+			types[rel.ForeignKey] = "int"
+		}
+	}
+
+	header := records[0]
+	rows := make([]map[string]any, 0, len(records)-1)
+	for line, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, column := range header {
+			value, err := csvValue(record[i], types[column])
+			if err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %w", line+2, column, err)
+			}
+			row[column] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func csvValue(raw, typ string) (any, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	switch typ {
+	case "int", "int64":
+		return strconv.ParseInt(raw, 10, 64)
+	case "float", "decimal":
+		return strconv.ParseFloat(raw, 64)
+	case "bool":
+		return strconv.ParseBool(raw)
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"gopkg.in/yaml.v3"
+)
+
+// Service is one service of a monorepo, generated as a module of its own
+// under services/<name>. It may be written either as a plain name
+// (`- users`) or as an object with the settings of a project spec.
+type Service struct {
+	Name        string                 `yaml:"name"`
+	Type        string                 `yaml:"type,omitempty"`
+	Arch        string                 `yaml:"arch,omitempty"`
+	Port        int                    `yaml:"port,omitempty"`
+	Database    string                 `yaml:"db,omitempty"`
+	Router      string                 `yaml:"router,omitempty"`
+	Features    Features               `yaml:"features,omitempty"`
+	Databases   map[string]Datastore   `yaml:"databases,omitempty"`
+	Modules     []Module               `yaml:"modules,omitempty"`
+	Entities    []Entity               `yaml:"entities,omitempty"`
+	CustomLogic map[string][]Operation `yaml:"custom_logic,omitempty"`
+	Commands    []Command              `yaml:"commands,omitempty"`
+	Jobs        []Job                  `yaml:"jobs,omitempty"`
+}
+
+func (s *Service) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Name = node.Value
+		return nil
+	}
+
+	type plain Service
+	return node.Decode((*plain)(s))
+}
+
+func (s Service) MarshalYAML() (interface{}, error) {
+	if s.Type == "" && s.Arch == "" && s.Port == 0 && s.Database == "" && s.Router == "" &&
+		len(s.Features.Enabled()) == 0 && len(s.Databases) == 0 && len(s.Modules) == 0 &&
+		len(s.Entities) == 0 && len(s.CustomLogic) == 0 && len(s.Commands) == 0 && len(s.Jobs) == 0 {
+		return s.Name, nil
+	}
+
+	type plain Service
+	return plain(s), nil
+}
+
+// Config returns the project spec of the service. The type, arch and
+// router of the root project are defaults for every service, and the root
+// profiles are shared by all of them. The arch is only inherited along
+// with the type, and the router only by types that serve HTTP.
+func (s Service) Config(root *Config) *Config {
+	project := Project{
+		Name:     s.Name,
+		Type:     s.Type,
+		Arch:     s.Arch,
+		Port:     s.Port,
+		Database: s.Database,
+		Router:   s.Router,
+	}
+	if project.Type == "" {
+		project.Type = root.Project.Type
+		if project.Arch == "" {
+			project.Arch = root.Project.Arch
+		}
+	}
+	if project.Router == "" && servesHTTP(project.Type) {
+		project.Router = root.Project.Router
+	}
+
+	return &Config{
+		Project:     project,
+		Features:    s.Features,
+		Profiles:    root.Profiles,
+		Databases:   s.Databases,
+		Modules:     s.Modules,
+		Entities:    s.Entities,
+		CustomLogic: s.CustomLogic,
+		Commands:    s.Commands,
+		Jobs:        s.Jobs,
+	}
+}
+
+// Service looks up a declared service by name.
+func (c *Config) Service(name string) (Service, bool) {
+	for _, service := range c.Services {
+		if service.Name == name {
+			return service, true
+		}
+	}
+	return Service{}, false
+}
+
+func servesHTTP(projectType string) bool {
+	if projectType == "" {
+		projectType = layout.DefaultType
+	}
+	t, ok := layout.TypeRegistory[projectType]
+	return ok && t.HTTP
+}
+
+var serviceName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// serviceKeys are the root keys that belong to a single service once a
+// spec declares services.
+var serviceKeys = []struct {
+	path []any
+	name string
+	set  func(c *Config) bool
+}{
+	{[]any{"project", "port"}, "port", func(c *Config) bool { return c.Project.Port != 0 }},
+	{[]any{"project", "db"}, "db", func(c *Config) bool { return c.Project.Database != "" }},
+	{[]any{"features"}, "features", func(c *Config) bool { return len(c.Features.Enabled()) > 0 }},
+	{[]any{"databases"}, "databases", func(c *Config) bool { return len(c.Databases) > 0 }},
+	{[]any{"modules"}, "modules", func(c *Config) bool { return len(c.Modules) > 0 }},
+	{[]any{"entities"}, "entities", func(c *Config) bool { return len(c.Entities) > 0 }},
+	{[]any{"custom_logic"}, "custom_logic", func(c *Config) bool { return len(c.CustomLogic) > 0 }},
+	{[]any{"commands"}, "commands", func(c *Config) bool { return len(c.Commands) > 0 }},
+	{[]any{"jobs"}, "jobs", func(c *Config) bool { return len(c.Jobs) > 0 }},
+}
+
+// checkServices reports monorepo specs that cannot be generated: settings
+// of a single service at the root, invalid or duplicate service names,
+// ports used twice, and every issue of each service's own spec.
+func (c *Config) checkServices(doc *yaml.Node) []Issue {
+	if len(c.Services) == 0 {
+		return nil
+	}
+
+	var issues []Issue
+	add := func(node *yaml.Node, format string, args ...any) {
+		issues = append(issues, nodeIssue(node, fmt.Sprintf(format, args...)))
+	}
+
+	for _, key := range serviceKeys {
+		if key.set(c) {
+			add(lookup(doc, key.path...), "%s must be set on each service, not on a project with services", key.name)
+		}
+	}
+
+	names := map[string]bool{}
+	ports := map[int]string{}
+	for i, service := range c.Services {
+		switch {
+		case service.Name == "":
+			add(lookup(doc, "services", i), "service without a name")
+			continue
+		case !serviceName.MatchString(service.Name):
+			add(lookup(doc, "services", i, "name"), "service name %q must be lowercase words separated by dashes", service.Name)
+		case names[service.Name]:
+			add(lookup(doc, "services", i, "name"), "service %q is declared twice", service.Name)
+		}
+		names[service.Name] = true
+
+		if other, ok := ports[service.Port]; ok && service.Port != 0 {
+			add(lookup(doc, "services", i, "port"), "service %q uses port %d of service %q", service.Name, service.Port, other)
+		} else {
+			ports[service.Port] = service.Name
+		}
+
+		// Profiles are shared and were checked with the root.
+		spec := service.Config(c)
+		spec.Profiles = nil
+		issues = append(issues, spec.check(serviceDoc(doc, i))...)
+	}
+	return issues
+}
+
+// serviceDoc builds the document a service's spec would have been decoded
+// from, out of the nodes of the service and those it inherits from the
+// root project, so that its issues point into the file.
+func serviceDoc(doc *yaml.Node, i int) *yaml.Node {
+	node := lookup(doc, "services", i)
+	value := func(mapping *yaml.Node, key string) *yaml.Node {
+		if mapping.Kind != yaml.MappingNode {
+			return nil
+		}
+		if j := indexOfKey(mapping, key); j >= 0 {
+			return mapping.Content[j+1]
+		}
+		return nil
+	}
+	rootProject := value(doc, "project")
+	if rootProject == nil {
+		rootProject = &yaml.Node{Kind: yaml.MappingNode}
+	}
+
+	own := map[string]*yaml.Node{}
+	for _, key := range projectKeys {
+		own[key] = value(node, key)
+	}
+	if node.Kind == yaml.ScalarNode {
+		own["name"] = node
+	}
+	// Inherited as in Service.Config; a router the type takes none of is
+	// dropped there, so its node is never looked up.
+	if own["type"] == nil {
+		own["type"] = value(rootProject, "type")
+		if own["arch"] == nil {
+			own["arch"] = value(rootProject, "arch")
+		}
+	}
+	if own["router"] == nil {
+		own["router"] = value(rootProject, "router")
+	}
+
+	project := &yaml.Node{Kind: yaml.MappingNode, Line: node.Line, Column: node.Column}
+	for _, key := range projectKeys {
+		if own[key] != nil {
+			project.Content = append(project.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, own[key])
+		}
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Line: node.Line, Column: node.Column}
+	root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "project"}, project)
+	if node.Kind == yaml.MappingNode {
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if !contains(projectKeys, node.Content[j].Value) {
+				root.Content = append(root.Content, node.Content[j], node.Content[j+1])
+			}
+		}
+	}
+	return root
+}
+
+// projectKeys are the keys of a service that go to the project section of
+// its spec.
+var projectKeys = []string{"name", "type", "arch", "port", "db", "router"}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_CheckServices(t *testing.T) {
+	const project = "project:\n  name: shop\n  router: chi\nservices:\n"
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "valid", spec: `
+  - users
+  - name: orders
+    port: 8081
+    db: postgres
+    entities: [order]
+  - name: mailer
+    type: worker
+    jobs: [send_email]
+`},
+		{name: "invalid name", spec: "  - Users\n",
+			wantErr: `p.yaml:5:5: service name "Users" must be lowercase words separated by dashes`},
+		{name: "duplicate", spec: "  - users\n  - name: users\n",
+			wantErr: `p.yaml:6:11: service "users" is declared twice`},
+		{name: "port used twice", spec: "  - { name: users, port: 8081 }\n  - { name: orders, port: 8081 }\n",
+			wantErr: `p.yaml:6:27: service "orders" uses port 8081 of service "users"`},
+		{name: "issue of a service", spec: "  - name: users\n    db: oracle\n",
+			wantErr: `p.yaml:6:9: unknown database "oracle" (expected one of cockroachdb, mariadb, mysql, postgres, sqlite)`},
+		{name: "entities on a worker service", spec: "  - name: mailer\n    type: worker\n    entities: [user]\n",
+			wantErr: `p.yaml:7:15: worker projects take no entities`},
+		{name: "unknown key in a service", spec: "  - name: users\n    database: postgres\n",
+			wantErr: `p.yaml:6:5: unknown field "database" in services[0] (did you mean "db"?)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := DecodeYAML([]byte(project+tt.spec), "p.yaml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Len(t, config.Services, 3)
+				assert.Equal(t, "users", config.Services[0].Name)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfig_CheckServices_RootKeys(t *testing.T) {
+	_, err := DecodeYAML([]byte("project:\n  name: shop\n  db: postgres\nentities: [user]\nservices: [users]\n"), "p.yaml")
+	assert.EqualError(t, err, "p.yaml:3:7: db must be set on each service, not on a project with services\n"+
+		"p.yaml:4:11: entities must be set on each service, not on a project with services")
+}
+
+func TestConfig_CheckServices_InheritedIssueOnce(t *testing.T) {
+	_, err := DecodeYAML([]byte("project:\n  name: shop\n  router: gim\nservices: [users, orders]\n"), "p.yaml")
+	assert.EqualError(t, err, `p.yaml:3:11: unknown router "gim" (expected one of chi, echo, fiber, gin, mux)`)
+}
+
+func TestService_Config(t *testing.T) {
+	root := &Config{
+		Project:  Project{Name: "shop", Type: "rest", Arch: "hexagonal", Router: "chi"},
+		Profiles: []Profile{{Name: "prod"}},
+	}
+
+	users := Service{Name: "users", Port: 8081, Entities: []Entity{{Name: "user"}}}.Config(root)
+	assert.Equal(t, Project{Name: "users", Type: "rest", Arch: "hexagonal", Port: 8081, Router: "chi"}, users.Project)
+	assert.Equal(t, root.Profiles, users.Profiles)
+	assert.Equal(t, []string{"user"}, users.EntityNames())
+
+	// Another type keeps its own default arch and takes no router.
+	mailer := Service{Name: "mailer", Type: "worker"}.Config(root)
+	assert.Equal(t, Project{Name: "mailer", Type: "worker"}, mailer.Project)
+}
+
+func TestService_Marshal(t *testing.T) {
+	out, err := yaml.Marshal([]Service{{Name: "users"}, {Name: "orders", Port: 8081}})
+	require.NoError(t, err)
+	assert.Equal(t, "- users\n- name: orders\n  port: 8081\n", string(out))
+}
@@ -0,0 +1,416 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/upsaurav12/bootstrap/pkg/addons"
+	"github.com/upsaurav12/bootstrap/pkg/framework"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is one problem found in a project file, at the position it was
+// found. Column is 0 when only the line is known. File is set when the
+// problem is in a file the project file extends.
+type Issue struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+
+	node *yaml.Node
+}
+
+func nodeIssue(node *yaml.Node, msg string) Issue {
+	return Issue{Line: node.Line, Column: node.Column, Message: msg, node: node}
+}
+
+func (i Issue) String() string {
+	if i.Column == 0 {
+		return fmt.Sprintf("%d: %s", i.Line, i.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, i.Message)
+}
+
+// ValidationError lists every issue found in a project file.
+type ValidationError struct {
+	File   string
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		file := issue.File
+		if file == "" {
+			file = e.File
+		}
+		lines[i] = file + ":" + issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DecodeYAML strictly decodes a project file: unknown keys, values of the
+// wrong type and settings that cannot be generated are all reported, with
+// their position, in a *ValidationError. file names the source in messages
+// and locates the files it extends.
+func DecodeYAML(data []byte, file string) (*Config, error) {
+	s, err := loadSpec(data, file)
+	if err != nil {
+		return nil, err
+	}
+	doc := s.doc
+
+	issues := checkKnownFields(doc, reflect.TypeOf(Config{}), "")
+
+	var config Config
+	if err := doc.Decode(&config); err != nil {
+		issues = append(issues, typeIssues(err)...)
+	} else {
+		issues = append(issues, config.check(doc)...)
+	}
+
+	if len(issues) > 0 {
+		issues = uniqueIssues(issues)
+		for i := range issues {
+			if origin := s.origins[issues[i].node]; origin != file {
+				issues[i].File = origin
+			}
+		}
+		sort.SliceStable(issues, func(i, j int) bool {
+			if issues[i].File != issues[j].File {
+				return issues[i].File < issues[j].File
+			}
+			if issues[i].Line != issues[j].Line {
+				return issues[i].Line < issues[j].Line
+			}
+			return issues[i].Column < issues[j].Column
+		})
+		return nil, &ValidationError{File: file, Issues: issues}
+	}
+	return &config, nil
+}
+
+// uniqueIssues drops issues reported twice at the same node, as happens
+// when the services of a spec inherit a bad setting of its root project.
+func uniqueIssues(issues []Issue) []Issue {
+	type key struct {
+		node    *yaml.Node
+		message string
+	}
+	seen := map[key]bool{}
+	out := issues[:0]
+	for _, issue := range issues {
+		k := key{issue.node, issue.Message}
+		if issue.node != nil && seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, issue)
+	}
+	return out
+}
+
+// checkKnownFields walks node alongside the Go type it decodes into and
+// reports keys that type has no field for. yaml.v3's KnownFields does not
+// reach into types with their own UnmarshalYAML, such as Entity, so the
+// check is done on the node tree instead.
+func checkKnownFields(node *yaml.Node, t reflect.Type, where string) []Issue {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Seed rows are free-form.
+	if t == reflect.TypeOf(Seeds{}) {
+		return nil
+	}
+
+	var issues []Issue
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				issues = append(issues, nodeIssue(key, unknownField(key.Value, where, fields)))
+				continue
+			}
+			issues = append(issues, checkKnownFields(value, field.Type, join(where, key.Value))...)
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			issues = append(issues, checkKnownFields(value, t.Elem(), join(where, key.Value))...)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			issues = append(issues, checkKnownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", where, i))...)
+		}
+	}
+	return issues
+}
+
+// yamlFields maps the yaml keys of a struct type to its fields.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// misspelledKeys are keys people commonly write instead of the real one.
+var misspelledKeys = map[string]string{
+	"database":   "db",
+	"framework":  "router",
+	"kind":       "type",
+	"layout":     "arch",
+	"entity":     "entities",
+	"relation":   "relations",
+	"field":      "fields",
+	"profile":    "profiles",
+	"module":     "modules",
+	"validation": "validate",
+}
+
+func unknownField(key, where string, fields map[string]reflect.StructField) string {
+	msg := fmt.Sprintf("unknown field %q", key)
+	if where != "" {
+		msg += " in " + where
+	}
+
+	if want, ok := misspelledKeys[key]; ok {
+		if _, exists := fields[want]; exists {
+			return msg + fmt.Sprintf(" (did you mean %q?)", want)
+		}
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return msg + " (expected one of " + strings.Join(names, ", ") + ")"
+}
+
+func join(where, key string) string {
+	if where == "" {
+		return key
+	}
+	return where + "." + key
+}
+
+var typeErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// typeIssues turns the errors of yaml.v3 decoding into issues. They only
+// carry a line.
+func typeIssues(err error) []Issue {
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		return []Issue{{Line: 1, Message: err.Error()}}
+	}
+
+	var issues []Issue
+	for _, msg := range typeErr.Errors {
+		issue := Issue{Message: msg}
+		if m := typeErrorLine.FindStringSubmatch(msg); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+			issue.Message = m[2]
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// check reports settings that decode fine but cannot be generated. doc is
+// the node the config was decoded from, used to locate each issue.
+func (c *Config) check(doc *yaml.Node) []Issue {
+	var issues []Issue
+	add := func(node *yaml.Node, format string, args ...any) {
+		issues = append(issues, nodeIssue(node, fmt.Sprintf(format, args...)))
+	}
+
+	projectType := c.Project.Type
+	if projectType == "" {
+		projectType = layout.DefaultType
+	}
+	if _, ok := layout.TypeRegistory[projectType]; !ok {
+		add(lookup(doc, "project", "type"), "unknown project type %q (expected one of %s)",
+			projectType, strings.Join(layout.Types(), ", "))
+	} else if _, ok := layout.Lookup(projectType, c.Project.Arch); !ok {
+		add(lookup(doc, "project", "arch"), "unknown arch %q for %s projects (expected one of %s)",
+			c.Project.Arch, projectType, strings.Join(layout.Archs(projectType), ", "))
+	}
+	// Types that do not serve HTTP have no router to run features in.
+	t, ok := layout.TypeRegistory[projectType]
+	noHTTP := ok && !t.HTTP
+	if c.Project.Router != "" {
+		if noHTTP {
+			add(lookup(doc, "project", "router"), "%s projects take no router", projectType)
+		} else if _, ok := framework.FrameworkRegistory[c.Project.Router]; !ok {
+			add(lookup(doc, "project", "router"), "unknown router %q (expected one of %s)",
+				c.Project.Router, strings.Join(sortedKeys(framework.FrameworkRegistory), ", "))
+		}
+	}
+	if c.Project.Database != "" {
+		if databases := addons.Databases(); !contains(databases, c.Project.Database) {
+			add(lookup(doc, "project", "db"), "unknown database %q (expected one of %s)",
+				c.Project.Database, strings.Join(databases, ", "))
+		}
+	}
+	if c.Project.Port < 0 || c.Project.Port > 65535 {
+		add(lookup(doc, "project", "port"), "port %d is out of range (1-65535)", c.Project.Port)
+	} else if c.Project.Port != 0 && ok && t.Healthcheck == nil {
+		add(lookup(doc, "project", "port"), "%s projects take no port", projectType)
+	}
+	// Only some types keep data, generate entities, commands or jobs.
+	if ok && !t.Databases {
+		if c.Project.Database != "" {
+			add(lookup(doc, "project", "db"), "%s projects take no database", projectType)
+		}
+		if len(c.Databases) > 0 {
+			add(lookup(doc, "databases"), "%s projects take no databases", projectType)
+		}
+	}
+	if ok && !t.Entities && len(c.Entities) > 0 {
+		add(lookup(doc, "entities"), "%s projects take no entities", projectType)
+	}
+	if ok && !t.Commands && len(c.Commands) > 0 {
+		add(lookup(doc, "commands"), "%s projects take no commands", projectType)
+	}
+	if ok && !t.Jobs && len(c.Jobs) > 0 {
+		add(lookup(doc, "jobs"), "%s projects take no jobs", projectType)
+	}
+
+	providers := addons.FeatureProviders()
+	enabled := c.Features.Enabled()
+	for _, kind := range sortedKeys(enabled) {
+		if noHTTP {
+			add(lookup(doc, "features", kind), "%s projects do not support features yet", projectType)
+		} else if !contains(providers[kind], enabled[kind]) {
+			add(lookup(doc, "features", kind), "unknown %s provider %q (expected one of %s)",
+				kind, enabled[kind], strings.Join(providers[kind], ", "))
+		}
+	}
+
+	profiles := map[string]bool{}
+	for i, profile := range c.Profiles {
+		if err := profile.check(); err != nil {
+			add(lookup(doc, "profiles", i), "%v", err)
+		} else if profiles[profile.Name] {
+			add(lookup(doc, "profiles", i), "profile %q is declared twice", profile.Name)
+		}
+		profiles[profile.Name] = true
+	}
+
+	for _, name := range sortedKeys(c.Databases) {
+		store := c.Databases[name]
+		switch databases := addons.Databases(); {
+		case store.Database == "":
+			add(lookup(doc, "databases", name), "database %q has no db", name)
+		case !contains(databases, store.Database):
+			add(lookup(doc, "databases", name, "db"), "unknown database %q (expected one of %s)",
+				store.Database, strings.Join(databases, ", "))
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, entity := range c.Entities {
+		node := lookup(doc, "entities", i)
+		if entity.Name == "" {
+			add(node, "entity without a name")
+			continue
+		}
+		if err := CheckEntityName(entity.Name); err != nil {
+			add(lookup(doc, "entities", i, "name"), "%v", err)
+		} else if seen[strings.ToLower(entity.Name)] {
+			add(lookup(doc, "entities", i, "name"), "entity %q is declared twice", entity.Name)
+		}
+		seen[strings.ToLower(entity.Name)] = true
+
+		if entity.Store != "" {
+			_, declared := c.Databases[entity.Store]
+			if !declared && (len(c.Databases) > 0 || entity.Store != "primary") {
+				add(lookup(doc, "entities", i, "store"), "store %q is not declared under databases", entity.Store)
+			}
+		}
+		if entity.Realtime && ok && !t.Realtime {
+			add(lookup(doc, "entities", i, "realtime"), "%s projects take no realtime entities", projectType)
+		}
+		if len(entity.Fields) > 0 {
+			if err := entity.CheckFields(); err != nil {
+				add(lookup(doc, "entities", i, "fields"), "%v", err)
+			}
+		}
+	}
+
+	issues = append(issues, c.checkModules(doc)...)
+	issues = append(issues, c.checkCommands(doc)...)
+	issues = append(issues, c.checkJobs(doc)...)
+	issues = append(issues, c.checkServices(doc)...)
+
+	for _, entity := range sortedKeys(c.CustomLogic) {
+		if err := c.checkOperations(entity); err != nil {
+			add(lookup(doc, "custom_logic", entity), "%v", err)
+		}
+	}
+
+	if len(issues) == 0 {
+		if err := c.CheckRelations(); err != nil {
+			add(lookup(doc, "entities"), "%v", err)
+		}
+	}
+	return issues
+}
+
+// lookup finds the node at path, made of mapping keys and sequence
+// indexes. When the path does not exist it returns the deepest node found,
+// so that issues still point close to their cause.
+func lookup(node *yaml.Node, path ...any) *yaml.Node {
+	for _, step := range path {
+		var next *yaml.Node
+		switch step := step.(type) {
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == step {
+						next = node.Content[i+1]
+					}
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && step < len(node.Content) {
+				next = node.Content[step]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "valid", spec: `
+project: { name: shop, router: gin, db: postgres, port: 8080 }
+entities:
+  - user
+  - name: order
+    fields: [{ name: total, type: decimal }]
+`},
+		{name: "unknown key with suggestion", spec: "project:\n  name: shop\n  database: postgres\n",
+			wantErr: `p.yaml:3:3: unknown field "database" in project (did you mean "db"?)`},
+		{name: "unknown key in entity object", spec: "entities:\n  - name: user\n    feilds: []\n",
+			wantErr: `p.yaml:3:5: unknown field "feilds" in entities[0] (expected one of fields, name, realtime, relations, seeds, store)`},
+		{name: "unknown router", spec: "project:\n  router: gim\n",
+			wantErr: `p.yaml:2:11: unknown router "gim" (expected one of chi, echo, fiber, gin, mux)`},
+		{name: "unknown database", spec: "databases:\n  main: { db: oracle }\n",
+			wantErr: `p.yaml:2:15: unknown database "oracle" (expected one of cockroachdb, mariadb, mysql, postgres, sqlite)`},
+		{name: "database without repositories", spec: "project:\n  db: mongodb\n",
+			wantErr: `p.yaml:2:7: unknown database "mongodb" (expected one of cockroachdb, mariadb, mysql, postgres, sqlite)`},
+		{name: "port out of range", spec: "project:\n  port: 0x10000\n",
+			wantErr: `p.yaml:2:9: port 65536 is out of range (1-65535)`},
+		{name: "wrong type", spec: "project:\n  port: eighty\n",
+			wantErr: "p.yaml:2: cannot unmarshal !!str `eighty` into int"},
+		{name: "invalid entity name", spec: "entities:\n  - user\n  - order-item\n",
+			wantErr: `p.yaml:3:5: entity name "order-item" is not a valid Go identifier`},
+		{name: "reserved entity name", spec: "entities:\n  - user\n  - errors\n",
+			wantErr: `p.yaml:3:5: entity name "errors" is reserved by the generated code`},
+		{name: "duplicate entity", spec: "entities:\n  - user\n  - name: User\n",
+			wantErr: `p.yaml:3:11: entity "User" is declared twice`},
+		{name: "unknown project type", spec: "project:\n  type: soap\n",
+			wantErr: `p.yaml:2:9: unknown project type "soap" (expected one of cli, graphql, grpc, library, rest, worker)`},
+		{name: "router on grpc", spec: "project:\n  type: grpc\n  router: gin\n",
+			wantErr: `p.yaml:3:11: grpc projects take no router`},
+		{name: "features on grpc", spec: "project:\n  type: grpc\nfeatures:\n  cache: redis\n",
+			wantErr: `p.yaml:4:10: grpc projects do not support features yet`},
+		{name: "entities on cli", spec: "project:\n  type: cli\nentities:\n  - user\n",
+			wantErr: `p.yaml:4:3: cli projects take no entities`},
+		{name: "port on cli", spec: "project:\n  type: cli\n  port: 8080\n",
+			wantErr: `p.yaml:3:9: cli projects take no port`},
+		{name: "commands on rest", spec: "commands:\n  - serve\n",
+			wantErr: `p.yaml:2:3: rest projects take no commands`},
+		{name: "entities on worker", spec: "project:\n  type: worker\n  db: postgres\nentities:\n  - user\n",
+			wantErr: `p.yaml:5:3: worker projects take no entities`},
+		{name: "db on library", spec: "project:\n  type: library\n  db: postgres\n",
+			wantErr: `p.yaml:3:7: library projects take no database`},
+		{name: "realtime on graphql", spec: "project:\n  type: graphql\nentities:\n  - name: user\n    realtime: true\n",
+			wantErr: `p.yaml:5:15: graphql projects take no realtime entities`},
+		{name: "jobs on rest", spec: "jobs:\n  - send_email\n",
+			wantErr: `p.yaml:2:3: rest projects take no jobs`},
+		{name: "unknown arch", spec: "project:\n  type: rest\n  arch: onion\n",
+			wantErr: `p.yaml:3:9: unknown arch "onion" for rest projects (expected one of clean, hexagonal, minimal, modular)`},
+		{name: "several issues in file order", spec: "project:\n  layout: clean\n  router: gim\n",
+			wantErr: "p.yaml:2:3: unknown field \"layout\" in project (did you mean \"arch\"?)\n" +
+				`p.yaml:3:11: unknown router "gim" (expected one of chi, echo, fiber, gin, mux)`},
+		{name: "unknown feature provider", spec: "features:\n  cache: memcached\n",
+			wantErr: `p.yaml:2:10: unknown cache provider "memcached" (expected one of redis)`},
+		{name: "unknown feature", spec: "features:\n  mail: smtp\n",
+			wantErr: `p.yaml:2:3: unknown field "mail" in features (expected one of auth, cache, queue)`},
+		{name: "reserved profile", spec: "profiles:\n  - dev\n  - local\n",
+			wantErr: `p.yaml:3:5: profile "local" is reserved for .env`},
+		{name: "duplicate profile", spec: "profiles:\n  - dev\n  - name: dev\n",
+			wantErr: `p.yaml:3:5: profile "dev" is declared twice`},
+		{name: "invalid profile env key", spec: "profiles:\n  - name: prod\n    env: { db-host: x }\n",
+			wantErr: `p.yaml:2:5: profile "prod": "db-host" is not a valid environment variable name`},
+		{name: "empty", spec: "", wantErr: "p.yaml:1: file is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := DecodeYAML([]byte(tt.spec), "p.yaml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"user", "order"}, config.EntityNames())
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFeatures_Decode(t *testing.T) {
+	config, err := DecodeYAML([]byte("features:\n  cache: redis\n  queue: { name: rabbitmq }\n"), "p.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cache": "redis", "queue": "rabbitmq"}, config.Features.Enabled())
+
+	out, err := yaml.Marshal(config.Features)
+	require.NoError(t, err)
+	assert.Equal(t, "cache: redis\nqueue: rabbitmq\n", string(out))
+}
+
+func TestProfiles_Decode(t *testing.T) {
+	config, err := DecodeYAML([]byte("profiles:\n  - dev\n  - name: prod\n    env: { GONE_DB_HOST: db.internal }\n"), "p.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []Profile{{Name: "dev"}, {Name: "prod", Env: map[string]string{"GONE_DB_HOST": "db.internal"}}}, config.Profiles)
+
+	out, err := yaml.Marshal(config.Profiles)
+	require.NoError(t, err)
+	assert.Equal(t, "- dev\n- name: prod\n  env:\n    GONE_DB_HOST: db.internal\n", string(out))
+}
@@ -1,58 +1,208 @@
 package parser
 
 import (
-	"fmt"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Project Project `yaml:"project"`
-	// Feature     Feature  `yaml:"feature"`
-	Entities    []string `yaml:"entities"`
-	CustomLogic []string `yaml:"custom_logic"`
+	Project     Project                `yaml:"project"`
+	Services    []Service              `yaml:"services,omitempty"`
+	Features    Features               `yaml:"features,omitempty"`
+	Profiles    []Profile              `yaml:"profiles,omitempty"`
+	Databases   map[string]Datastore   `yaml:"databases,omitempty"`
+	Modules     []Module               `yaml:"modules,omitempty"`
+	Entities    []Entity               `yaml:"entities,omitempty"`
+	CustomLogic map[string][]Operation `yaml:"custom_logic,omitempty"`
+	Commands    []Command              `yaml:"commands,omitempty"`
+	Jobs        []Job                  `yaml:"jobs,omitempty"`
+}
+
+// Datastore is one named database of the project. Entities bind to it with
+// their store key.
+type Datastore struct {
+	Database string `yaml:"db"`
+}
+
+// Module is a bounded context of a modular project: a group of the
+// declared entities that is generated as a package of its own.
+type Module struct {
+	Name     string   `yaml:"name"`
+	Entities []string `yaml:"entities"`
+}
+
+// Entity is one item of the entities list. It may be written either as a
+// plain name (`- user`) or as an object with typed fields and relations.
+type Entity struct {
+	Name      string     `yaml:"name"`
+	Store     string     `yaml:"store,omitempty"`
+	Fields    []Field    `yaml:"fields,omitempty"`
+	Relations []Relation `yaml:"relations,omitempty"`
+	Seeds     *Seeds     `yaml:"seeds,omitempty"`
+	// Realtime entities publish their changes, which clients follow at
+	// /api/v1/<entity>/stream.
+	Realtime bool `yaml:"realtime,omitempty"`
+}
+
+type Field struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"`
+	JSON       string `yaml:"json,omitempty"`
+	Nullable   bool   `yaml:"nullable,omitempty"`
+	Default    string `yaml:"default,omitempty"`
+	Unique     bool   `yaml:"unique,omitempty"`
+	Index      bool   `yaml:"index,omitempty"`
+	PrimaryKey bool   `yaml:"primary_key,omitempty"`
+	Validate   *Rules `yaml:"validate,omitempty"`
+}
+
+// Rules are the checks applied to a field in create and update requests.
+type Rules struct {
+	Required bool     `yaml:"required,omitempty"`
+	Min      *float64 `yaml:"min,omitempty"`
+	Max      *float64 `yaml:"max,omitempty"`
+	Email    bool     `yaml:"email,omitempty"`
+	OneOf    []string `yaml:"oneof,omitempty"`
+	Regex    string   `yaml:"regex,omitempty"`
+}
+
+type Relation struct {
+	Type       string `yaml:"type"`
+	Entity     string `yaml:"entity"`
+	Name       string `yaml:"name,omitempty"`
+	ForeignKey string `yaml:"foreign_key,omitempty"`
+	JoinTable  string `yaml:"join_table,omitempty"`
+	Required   bool   `yaml:"required,omitempty"`
+}
+
+func (e *Entity) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Name = node.Value
+		return nil
+	}
+
+	// The alias type drops these methods so Decode does not recurse.
+	type plain Entity
+	return node.Decode((*plain)(e))
+}
+
+func (e Entity) MarshalYAML() (interface{}, error) {
+	if len(e.Fields) == 0 && len(e.Relations) == 0 && e.Seeds == nil && e.Store == "" && !e.Realtime {
+		return e.Name, nil
+	}
+
+	type plain Entity
+	return plain(e), nil
+}
+
+// EntityNames returns the names of all declared entities, in order.
+func (c *Config) EntityNames() []string {
+	names := make([]string, 0, len(c.Entities))
+	for _, entity := range c.Entities {
+		names = append(names, entity.Name)
+	}
+	return names
 }
 
 type Project struct {
 	Name     string `yaml:"name"`
-	Type     string `yaml:"type"`
-	Port     int    `yaml:"port"`
-	Location string `yaml:"location"`
-	Database string `yaml:"db"`
-	Router   string `yaml:"router"`
+	Type     string `yaml:"type,omitempty"`
+	Arch     string `yaml:"arch,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Location string `yaml:"location,omitempty"`
+	Database string `yaml:"db,omitempty"`
+	Router   string `yaml:"router,omitempty"`
 }
 
-// type Feature struct {
-// 	Database FeatureItem `yaml:"database"`
-// 	Cache    FeatureItem `yaml:"cache"`
-// 	Queue    FeatureItem `yaml:"queue"`
-// 	Auth     FeatureItem `yaml:"auth"`
-// }
+// Profile is an environment the app is deployed to, such as staging. It may
+// be written either as a plain name (`- staging`) or as an object whose env
+// sets values of its .env.<name> file.
+type Profile struct {
+	Name string            `yaml:"name"`
+	Env  map[string]string `yaml:"env,omitempty"`
+}
+
+func (p *Profile) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		p.Name = node.Value
+		return nil
+	}
+
+	type plain Profile
+	return node.Decode((*plain)(p))
+}
+
+func (p Profile) MarshalYAML() (interface{}, error) {
+	if len(p.Env) == 0 {
+		return p.Name, nil
+	}
+
+	type plain Profile
+	return plain(p), nil
+}
+
+// Features are the optional capabilities of the generated app, each one
+// naming the addon that provides it.
+type Features struct {
+	Cache FeatureItem `yaml:"cache,omitempty"`
+	Queue FeatureItem `yaml:"queue,omitempty"`
+	Auth  FeatureItem `yaml:"auth,omitempty"`
+}
+
+// Enabled returns the provider of every enabled feature, keyed by its
+// features: key.
+func (f Features) Enabled() map[string]string {
+	enabled := map[string]string{}
+	for kind, item := range map[string]FeatureItem{"cache": f.Cache, "queue": f.Queue, "auth": f.Auth} {
+		if item.Name != "" {
+			enabled[kind] = item.Name
+		}
+	}
+	return enabled
+}
 
+// FeatureItem may be written either as the addon name (`cache: redis`) or
+// as an object (`cache: { name: redis }`).
 type FeatureItem struct {
 	Name string `yaml:"name"`
 }
 
-type ProjectYAML struct {
-	Name     string   `json:"name" yaml:"name"`
-	Layers   []string `json:"layers" yaml:"layers"`
-	Features []string `json:"features" yaml:"features"`
+func (f *FeatureItem) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		f.Name = node.Value
+		return nil
+	}
+
+	type plain FeatureItem
+	return node.Decode((*plain)(f))
+}
+
+func (f FeatureItem) MarshalYAML() (interface{}, error) {
+	return f.Name, nil
 }
 
+func (f FeatureItem) IsZero() bool {
+	return f.Name == ""
+}
+
+// ReadYAML reads and strictly validates a project file; see DecodeYAML.
 func ReadYAML(yamlPath string) (*Config, error) {
 	yamlByte, err := os.ReadFile(yamlPath)
 	if err != nil {
 		return &Config{}, err
 	}
 
-	var yamlProject Config
+	return DecodeYAML(yamlByte, yamlPath)
+}
 
-	err = yaml.Unmarshal(yamlByte, &yamlProject)
-	if err != nil {
-		fmt.Println("error: ", err)
-		return &Config{}, err
+// Entity looks up a declared entity by name, ignoring case.
+func (c *Config) Entity(name string) (Entity, bool) {
+	for _, entity := range c.Entities {
+		if strings.EqualFold(entity.Name, name) {
+			return entity, true
+		}
 	}
-
-	return &yamlProject, nil
+	return Entity{}, false
 }
@@ -0,0 +1,243 @@
+// The code below is adapted from protoc-gen-go-grpc v1.5.1
+// (google.golang.org/grpc/cmd/protoc-gen-go-grpc), run with its default
+// options. Only unary RPCs are supported.
+//
+// Copyright 2020 gRPC authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// grpcVersion is the protoc-gen-go-grpc release whose output is reproduced.
+const grpcVersion = "1.5.1"
+
+const (
+	contextPackage = protogen.GoImportPath("context")
+	grpcPackage    = protogen.GoImportPath("google.golang.org/grpc")
+	codesPackage   = protogen.GoImportPath("google.golang.org/grpc/codes")
+	statusPackage  = protogen.GoImportPath("google.golang.org/grpc/status")
+)
+
+// generateGRPC writes the _grpc.pb.go file of the services of file, if it
+// has any.
+func generateGRPC(gen *protogen.Plugin, file *protogen.File) error {
+	if len(file.Services) == 0 {
+		return nil
+	}
+	for _, service := range file.Services {
+		for _, method := range service.Methods {
+			if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+				return fmt.Errorf("%s: %s.%s streams, which needs protoc-gen-go-grpc", file.Desc.Path(), service.Desc.Name(), method.Desc.Name())
+			}
+		}
+	}
+
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_grpc.pb.go", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-grpc. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P("// - protoc-gen-go-grpc v", grpcVersion)
+	g.P("// - protoc             (unknown)")
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	g.P("// This is a compile-time assertion to ensure that this generated file")
+	g.P("// is compatible with the grpc package it is being compiled against.")
+	g.P("// Requires gRPC-Go v1.64.0 or later.")
+	g.P("const _ = ", grpcPackage.Ident("SupportPackageIsVersion9"))
+	g.P()
+	for _, service := range file.Services {
+		genService(file, g, service)
+	}
+	return nil
+}
+
+func genService(file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service) {
+	// Full method names.
+	g.P("const (")
+	for _, method := range service.Methods {
+		g.P(fullMethodSymbol(method), ` = "/`, service.Desc.FullName(), "/", method.Desc.Name(), `"`)
+	}
+	g.P(")")
+	g.P()
+
+	// Client interface.
+	clientName := service.GoName + "Client"
+	g.P("// ", clientName, " is the client API for ", service.GoName, " service.")
+	g.P("//")
+	g.P("// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.")
+	genServiceComments(g, service)
+	g.P("type ", clientName, " interface {")
+	for _, method := range service.Methods {
+		g.P(method.Comments.Leading, clientSignature(g, method))
+	}
+	g.P("}")
+	g.P()
+
+	// Client structure and factory.
+	g.P("type ", unexport(clientName), " struct {")
+	g.P("cc ", grpcPackage.Ident("ClientConnInterface"))
+	g.P("}")
+	g.P()
+	g.P("func New", clientName, " (cc ", grpcPackage.Ident("ClientConnInterface"), ") ", clientName, " {")
+	g.P("return &", unexport(clientName), "{cc}")
+	g.P("}")
+	g.P()
+
+	// Client methods.
+	for _, method := range service.Methods {
+		g.P("func (c *", unexport(service.GoName), "Client) ", clientSignature(g, method), "{")
+		g.P("cOpts := append([]", grpcPackage.Ident("CallOption"), "{", grpcPackage.Ident("StaticMethod()"), "}, opts...)")
+		g.P("out := new(", method.Output.GoIdent, ")")
+		g.P(`err := c.cc.Invoke(ctx, `, fullMethodSymbol(method), `, in, out, cOpts...)`)
+		g.P("if err != nil { return nil, err }")
+		g.P("return out, nil")
+		g.P("}")
+		g.P()
+	}
+
+	// Server interface.
+	serverType := service.GoName + "Server"
+	g.P("// ", serverType, " is the server API for ", service.GoName, " service.")
+	g.P("// All implementations must embed Unimplemented", serverType)
+	g.P("// for forward compatibility.")
+	genServiceComments(g, service)
+	g.P("type ", serverType, " interface {")
+	for _, method := range service.Methods {
+		g.P(method.Comments.Leading, serverSignature(g, method))
+	}
+	g.P("mustEmbedUnimplemented", serverType, "()")
+	g.P("}")
+	g.P()
+
+	// Unimplemented server, for forward compatibility.
+	g.P("// Unimplemented", serverType, " must be embedded to have")
+	g.P("// forward compatible implementations.")
+	g.P("//")
+	g.P("// NOTE: this should be embedded by value instead of pointer to avoid a nil")
+	g.P("// pointer dereference when methods are called.")
+	g.P("type Unimplemented", serverType, " struct {}")
+	g.P()
+	for _, method := range service.Methods {
+		g.P("func (Unimplemented", serverType, ") ", serverSignature(g, method), "{")
+		g.P("return nil,", statusPackage.Ident("Errorf"), "(", codesPackage.Ident("Unimplemented"), `, "method `, method.GoName, ` not implemented")`)
+		g.P("}")
+	}
+	g.P("func (Unimplemented", serverType, ") mustEmbedUnimplemented", serverType, "() {}")
+	g.P("func (Unimplemented", serverType, ") testEmbeddedByValue() {}")
+	g.P()
+
+	// Unsafe server, to opt out of forward compatibility.
+	g.P("// Unsafe", serverType, " may be embedded to opt out of forward compatibility for this service.")
+	g.P("// Use of this interface is not recommended, as added methods to ", serverType, " will")
+	g.P("// result in compilation errors.")
+	g.P("type Unsafe", serverType, " interface {")
+	g.P("mustEmbedUnimplemented", serverType, "()")
+	g.P("}")
+
+	// Server registration.
+	serviceDescVar := service.GoName + "_ServiceDesc"
+	g.P("func Register", service.GoName, "Server(s ", grpcPackage.Ident("ServiceRegistrar"), ", srv ", serverType, ") {")
+	g.P("// If the following call pancis, it indicates Unimplemented", serverType, " was")
+	g.P("// embedded by pointer and is nil.  This will cause panics if an")
+	g.P("// unimplemented method is ever invoked, so we test this at initialization")
+	g.P("// time to prevent it from happening at runtime later due to I/O.")
+	g.P("if t, ok := srv.(interface { testEmbeddedByValue() }); ok {")
+	g.P("t.testEmbeddedByValue()")
+	g.P("}")
+	g.P("s.RegisterService(&", serviceDescVar, `, srv)`)
+	g.P("}")
+	g.P()
+
+	// Server handlers.
+	handlerNames := make([]string, 0, len(service.Methods))
+	for _, method := range service.Methods {
+		handlerNames = append(handlerNames, genServerMethod(g, method))
+	}
+
+	// Service descriptor.
+	g.P("// ", serviceDescVar, " is the ", grpcPackage.Ident("ServiceDesc"), " for ", service.GoName, " service.")
+	g.P("// It's only intended for direct use with ", grpcPackage.Ident("RegisterService"), ",")
+	g.P("// and not to be introspected or modified (even as a copy)")
+	g.P("var ", serviceDescVar, " = ", grpcPackage.Ident("ServiceDesc"), " {")
+	g.P("ServiceName: ", strconv.Quote(string(service.Desc.FullName())), ",")
+	g.P("HandlerType: (*", serverType, ")(nil),")
+	g.P("Methods: []", grpcPackage.Ident("MethodDesc"), "{")
+	for i, method := range service.Methods {
+		g.P("{")
+		g.P("MethodName: ", strconv.Quote(string(method.Desc.Name())), ",")
+		g.P("Handler: ", handlerNames[i], ",")
+		g.P("},")
+	}
+	g.P("},")
+	g.P("Streams: []", grpcPackage.Ident("StreamDesc"), "{},")
+	g.P("Metadata: \"", file.Desc.Path(), "\",")
+	g.P("}")
+	g.P()
+}
+
+// genServiceComments copies the comments of the service in the proto file.
+func genServiceComments(g *protogen.GeneratedFile, service *protogen.Service) {
+	if service.Comments.Leading != "" {
+		g.P("//")
+		g.P(strings.TrimSpace(service.Comments.Leading.String()))
+	}
+}
+
+func genServerMethod(g *protogen.GeneratedFile, method *protogen.Method) string {
+	service := method.Parent
+	hname := fmt.Sprintf("_%s_%s_Handler", service.GoName, method.GoName)
+
+	g.P("func ", hname, "(srv interface{}, ctx ", contextPackage.Ident("Context"), ", dec func(interface{}) error, interceptor ", grpcPackage.Ident("UnaryServerInterceptor"), ") (interface{}, error) {")
+	g.P("in := new(", method.Input.GoIdent, ")")
+	g.P("if err := dec(in); err != nil { return nil, err }")
+	g.P("if interceptor == nil { return srv.(", service.GoName, "Server).", method.GoName, "(ctx, in) }")
+	g.P("info := &", grpcPackage.Ident("UnaryServerInfo"), "{")
+	g.P("Server: srv,")
+	g.P("FullMethod: ", fullMethodSymbol(method), ",")
+	g.P("}")
+	g.P("handler := func(ctx ", contextPackage.Ident("Context"), ", req interface{}) (interface{}, error) {")
+	g.P("return srv.(", service.GoName, "Server).", method.GoName, "(ctx, req.(*", method.Input.GoIdent, "))")
+	g.P("}")
+	g.P("return interceptor(ctx, in, info, handler)")
+	g.P("}")
+	g.P()
+	return hname
+}
+
+func clientSignature(g *protogen.GeneratedFile, method *protogen.Method) string {
+	return method.GoName + "(ctx " + g.QualifiedGoIdent(contextPackage.Ident("Context")) +
+		", in *" + g.QualifiedGoIdent(method.Input.GoIdent) +
+		", opts ..." + g.QualifiedGoIdent(grpcPackage.Ident("CallOption")) +
+		") (*" + g.QualifiedGoIdent(method.Output.GoIdent) + ", error)"
+}
+
+func serverSignature(g *protogen.GeneratedFile, method *protogen.Method) string {
+	return method.GoName + "(" + g.QualifiedGoIdent(contextPackage.Ident("Context")) +
+		", *" + g.QualifiedGoIdent(method.Input.GoIdent) +
+		") (*" + g.QualifiedGoIdent(method.Output.GoIdent) + ", error)"
+}
+
+func fullMethodSymbol(method *protogen.Method) string {
+	return method.Parent.GoName + "_" + method.GoName + "_FullMethodName"
+}
+
+func unexport(s string) string { return strings.ToLower(s[:1]) + s[1:] }
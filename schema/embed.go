@@ -0,0 +1,8 @@
+// Package schema holds the JSON Schema of project.yaml, for editors and
+// for `bootstrap validate --schema`.
+package schema
+
+import _ "embed"
+
+//go:embed project.schema.json
+var Project []byte
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upsaurav12/bootstrap/pkg/addons"
+	"github.com/upsaurav12/bootstrap/pkg/framework"
+	"github.com/upsaurav12/bootstrap/pkg/layout"
+	"github.com/upsaurav12/bootstrap/pkg/parser"
+)
+
+type node struct {
+	Properties  map[string]*node `json:"properties"`
+	Enum        []string         `json:"enum"`
+	Definitions map[string]*node `json:"definitions"`
+}
+
+// The schema is written by hand; these tests keep it in step with the
+// parser types and the registries.
+func TestProjectSchema_MatchesParser(t *testing.T) {
+	var root node
+	require.NoError(t, json.Unmarshal(Project, &root))
+	defs := root.Definitions
+
+	// extends is resolved away before decoding into Config.
+	assert.ElementsMatch(t, append(yamlKeys(parser.Config{}), "extends"), keys(root.Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Project{}), keys(root.Properties["project"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Entity{}), keys(defs["entity"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Field{}), keys(defs["field"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Rules{}), keys(defs["rules"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Features{}), keys(root.Properties["features"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Profile{}), keys(defs["profile"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Module{}), keys(defs["module"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Relation{}), keys(defs["relation"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Operation{}), keys(defs["operation"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Command{}), keys(defs["command"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Flag{}), keys(defs["flag"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Job{}), keys(defs["job"].Properties))
+	assert.ElementsMatch(t, yamlKeys(parser.Service{}), keys(defs["service"].Properties))
+
+	assert.Equal(t, parser.FieldTypeNames(), defs["field"].Properties["type"].Enum)
+	assert.Equal(t, []string{parser.BelongsTo, parser.HasMany, parser.ManyToMany}, defs["relation"].Properties["type"].Enum)
+	assert.Equal(t, parser.HTTPMethods, defs["operation"].Properties["method"].Enum)
+	assert.Equal(t, sortedKeys(parser.FlagTypes), defs["flag"].Properties["type"].Enum)
+	assert.Equal(t, sortedKeys(framework.FrameworkRegistory), root.Properties["project"].Properties["router"].Enum)
+	assert.Equal(t, addons.Databases(), defs["database"].Enum)
+	assert.Equal(t, layout.Types(), root.Properties["project"].Properties["type"].Enum)
+	archs := map[string]bool{}
+	for _, projectType := range layout.Types() {
+		for _, arch := range layout.Archs(projectType) {
+			archs[arch] = true
+		}
+	}
+	assert.Equal(t, sortedKeys(archs), root.Properties["project"].Properties["arch"].Enum)
+	for kind, providers := range addons.FeatureProviders() {
+		assert.Equal(t, providers, defs[kind].Enum, kind)
+	}
+}
+
+func yamlKeys(v any) []string {
+	t := reflect.TypeOf(v)
+	var out []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func keys(m map[string]*node) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
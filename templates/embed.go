@@ -9,6 +9,16 @@ import "embed"
 //
 
 //go:embed common/**
+//go:embed cli/**
+//go:embed library/**
+//go:embed shared/**
+//go:embed layers/**
+//go:embed graphql/**
+//go:embed grpc/**
+//go:embed worker/**
+//go:embed monorepo/**
 //go:embed rest/**
 //go:embed db/**
+//go:embed features/**
+//go:embed realtime/**
 var FS embed.FS